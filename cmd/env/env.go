@@ -0,0 +1,180 @@
+// Package env reads the configuration of the service from environment
+// variables, so that the service and its tools are configured the same way.
+// A setting that is missing or invalid takes its default value.
+package env
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/booking"
+	"azure.com/ecovo/reservation-service/pkg/cancellation"
+	"azure.com/ecovo/reservation-service/pkg/checkin"
+	"azure.com/ecovo/reservation-service/pkg/db"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"azure.com/ecovo/reservation-service/pkg/ratelimit"
+	"azure.com/ecovo/reservation-service/pkg/reconciliation"
+)
+
+// DB returns the configuration of the connection to the database.
+func DB() *db.Config {
+	connectionTimeout, err := time.ParseDuration(os.Getenv("DB_CONNECTION_TIMEOUT") + "s")
+	if err != nil {
+		connectionTimeout = db.DefaultConnectionTimeout
+	}
+
+	return &db.Config{
+		Host:              os.Getenv("DB_HOST"),
+		Username:          os.Getenv("DB_USERNAME"),
+		Password:          os.Getenv("DB_PASSWORD"),
+		Name:              os.Getenv("DB_NAME"),
+		ConnectionTimeout: connectionTimeout,
+	}
+}
+
+// TripServiceDomain returns the domain of the trip-service.
+func TripServiceDomain() string {
+	return os.Getenv("TRIP_SERVICE_DOMAIN")
+}
+
+// AuthCredentials returns the base64 encoded username and password other
+// services authenticate with, which are also used to authenticate with them.
+func AuthCredentials() string {
+	return os.Getenv("AUTH_CREDENTIALS")
+}
+
+// NATSURL returns the URL of the NATS server events are published to, or an
+// empty string if they are not.
+func NATSURL() string {
+	return os.Getenv("NATS_URL")
+}
+
+// HoldTTL returns how long seats are held.
+func HoldTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("HOLD_TTL") + "m")
+	if err != nil {
+		return hold.DefaultTTL
+	}
+
+	return ttl
+}
+
+// Cancellation returns the configuration of the cancellation policy.
+func Cancellation() *cancellation.Config {
+	freePeriod, err := time.ParseDuration(os.Getenv("CANCELLATION_FREE_PERIOD") + "h")
+	if err != nil {
+		freePeriod = cancellation.DefaultFreePeriod
+	}
+	lateFee, err := strconv.Atoi(os.Getenv("CANCELLATION_LATE_FEE"))
+	if err != nil {
+		lateFee = cancellation.DefaultLateFee
+	}
+	noShowFee, err := strconv.Atoi(os.Getenv("CANCELLATION_NO_SHOW_FEE"))
+	if err != nil {
+		noShowFee = cancellation.DefaultNoShowFee
+	}
+
+	return &cancellation.Config{
+		FreePeriod: freePeriod,
+		LateFee:    lateFee,
+		NoShowFee:  noShowFee,
+	}
+}
+
+// CheckIn returns the configuration of the check-in policy.
+func CheckIn() *checkin.Config {
+	radius, err := strconv.ParseFloat(os.Getenv("CHECK_IN_RADIUS"), 64)
+	if err != nil {
+		radius = checkin.DefaultRadius
+	}
+	opensBefore, err := time.ParseDuration(os.Getenv("CHECK_IN_OPENS_BEFORE") + "m")
+	if err != nil {
+		opensBefore = checkin.DefaultOpensBefore
+	}
+	gracePeriod, err := time.ParseDuration(os.Getenv("CHECK_IN_GRACE_PERIOD") + "m")
+	if err != nil {
+		gracePeriod = checkin.DefaultGracePeriod
+	}
+
+	return &checkin.Config{
+		Radius:      radius,
+		OpensBefore: opensBefore,
+		GracePeriod: gracePeriod,
+	}
+}
+
+// Booking returns the configuration of the booking policy.
+func Booking() *booking.Config {
+	maxActive, err := strconv.Atoi(os.Getenv("BOOKING_MAX_ACTIVE"))
+	if err != nil {
+		maxActive = booking.DefaultMaxActive
+	}
+	maxCancellations, err := strconv.Atoi(os.Getenv("BOOKING_MAX_CANCELLATIONS"))
+	if err != nil {
+		maxCancellations = booking.DefaultMaxCancellations
+	}
+	cancellationWindow, err := time.ParseDuration(os.Getenv("BOOKING_CANCELLATION_WINDOW") + "h")
+	if err != nil {
+		cancellationWindow = booking.DefaultCancellationWindow
+	}
+
+	blockedUsers := []entity.ID{}
+	for _, ID := range strings.Split(os.Getenv("BOOKING_BLOCKED_USERS"), ",") {
+		ID = strings.TrimSpace(ID)
+		if ID != "" {
+			blockedUsers = append(blockedUsers, entity.NewIDFromHex(ID))
+		}
+	}
+
+	return &booking.Config{
+		MaxActive:          maxActive,
+		MaxCancellations:   maxCancellations,
+		CancellationWindow: cancellationWindow,
+		AllowOverlaps:      os.Getenv("BOOKING_ALLOW_OVERLAPS") == "true",
+		BlockedUsers:       blockedUsers,
+	}
+}
+
+// ReconciliationInterval returns how often reservations are reconciled with the
+// trip-service.
+func ReconciliationInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("RECONCILIATION_INTERVAL") + "m")
+	if err != nil {
+		return reconciliation.DefaultInterval
+	}
+
+	return interval
+}
+
+// SkipMigrations returns whether or not the migrations are skipped when the
+// service starts.
+func SkipMigrations() bool {
+	return os.Getenv("DB_SKIP_MIGRATIONS") == "true"
+}
+
+// RateLimits returns the configuration of the rate limits.
+func RateLimits() *ratelimit.Config {
+	rules, err := ratelimit.ParseRules(os.Getenv("RATE_LIMITS"))
+	if err != nil || len(rules) == 0 {
+		rules, _ = ratelimit.ParseRules(ratelimit.DefaultRules)
+	}
+
+	return &ratelimit.Config{
+		Rules:             rules,
+		TrustForwardedFor: os.Getenv("RATE_LIMIT_TRUST_PROXY") == "true",
+	}
+}
+
+// RateLimitStore returns where the token buckets of the rate limits are
+// stored, either "memory" or "mongo" to share them between the instances of
+// the service.
+func RateLimitStore() string {
+	if os.Getenv("RATE_LIMIT_STORE") == "mongo" {
+		return "mongo"
+	}
+
+	return "memory"
+}
@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+)
+
+// BatchWait represents how long a trip loader waits for other trips to be
+// requested before loading the ones requested so far in a single batch.
+const BatchWait = 2 * time.Millisecond
+
+type contextKey string
+
+func (c contextKey) String() string {
+	return "graph." + string(c)
+}
+
+const (
+	// tripLoaderContextKey represents the key used to store and retrieve the
+	// trip loader of a query from its context.
+	tripLoaderContextKey = contextKey("tripLoader")
+)
+
+// A tripResult is the result of loading a trip. Its done channel is closed
+// once the trip is loaded.
+type tripResult struct {
+	done chan struct{}
+	trip *entity.Trip
+	err  error
+}
+
+// A tripLoader loads the trips requested while a query is resolved. The trips
+// requested at about the same time are loaded in a single batch, and every
+// trip is only loaded once.
+type tripLoader struct {
+	service trip.UseCase
+
+	mu      sync.Mutex
+	results map[entity.ID]*tripResult
+	pending []entity.ID
+}
+
+// newTripLoader creates a trip loader that loads trips through the trip
+// service.
+func newTripLoader(service trip.UseCase) *tripLoader {
+	return &tripLoader{
+		service: service,
+		results: make(map[entity.ID]*tripResult),
+	}
+}
+
+// Load loads the trip with the given ID, waiting for the batch it is part of
+// to be loaded.
+func (l *tripLoader) Load(ctx context.Context, ID entity.ID) (*entity.Trip, error) {
+	l.mu.Lock()
+	result, ok := l.results[ID]
+	if !ok {
+		result = &tripResult{done: make(chan struct{})}
+		l.results[ID] = result
+
+		l.pending = append(l.pending, ID)
+		if len(l.pending) == 1 {
+			time.AfterFunc(BatchWait, l.dispatch)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-result.done:
+		return result.trip, result.err
+	}
+}
+
+// dispatch loads the pending batch of trips. The trip-service does not support
+// retrieving many trips at once, so the trips of a batch are retrieved
+// concurrently.
+func (l *tripLoader) dispatch() {
+	l.mu.Lock()
+	IDs := l.pending
+	l.pending = nil
+	results := make([]*tripResult, len(IDs))
+	for i, ID := range IDs {
+		results[i] = l.results[ID]
+	}
+	l.mu.Unlock()
+
+	for i, ID := range IDs {
+		go func(result *tripResult, ID entity.ID) {
+			defer close(result.done)
+
+			result.trip, result.err = l.service.FindByID(ID)
+		}(results[i], ID)
+	}
+}
+
+// withTripLoader stores the trip loader of a query in its context.
+func withTripLoader(ctx context.Context, l *tripLoader) context.Context {
+	return context.WithValue(ctx, tripLoaderContextKey, l)
+}
+
+// loadTrip loads the trip with the given ID with the trip loader of the query.
+func loadTrip(ctx context.Context, ID entity.ID) (*entity.Trip, error) {
+	l, ok := ctx.Value(tripLoaderContextKey).(*tripLoader)
+	if !ok {
+		return nil, fmt.Errorf("graph: %s not found in context", tripLoaderContextKey)
+	}
+
+	return l.Load(ctx, ID)
+}
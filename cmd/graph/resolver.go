@@ -0,0 +1,343 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// A queryResolver resolves the queries of the GraphQL API.
+type queryResolver struct {
+	service reservation.UseCase
+}
+
+// Reservation resolves the reservation with the given ID, for its passenger or
+// the driver of its trip.
+func (q *queryResolver) Reservation(ctx context.Context, args struct{ ID graphql.ID }) (*reservationResolver, error) {
+	res, err := q.service.FindForUser(ctx, toID(args.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservationResolver{res, q.service}, nil
+}
+
+// Reservations resolves the reservations with the given IDs, as long as the
+// authenticated user is the passenger or the driver of each of them.
+func (q *queryResolver) Reservations(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*reservationResolver, error) {
+	if len(args.IDs) > reservation.MaximumBatchSize {
+		return nil, entity.NewFieldValidationError("ids", fmt.Sprintf("cannot contain more than %d reservations", reservation.MaximumBatchSize))
+	}
+
+	resolvers := make([]*reservationResolver, 0, len(args.IDs))
+	for _, ID := range args.IDs {
+		res, err := q.service.FindForUser(ctx, toID(ID))
+		if err != nil {
+			return nil, err
+		}
+		resolvers = append(resolvers, &reservationResolver{res, q.service})
+	}
+
+	return resolvers, nil
+}
+
+// TripReservations resolves the reservations made on the trip with the given
+// ID.
+func (q *queryResolver) TripReservations(ctx context.Context, args struct{ TripID graphql.ID }) ([]*reservationResolver, error) {
+	return findTripReservations(ctx, q.service, toID(args.TripID))
+}
+
+// A reservationResolver resolves the fields of a reservation.
+type reservationResolver struct {
+	res     *entity.Reservation
+	service reservation.UseCase
+}
+
+func (r *reservationResolver) ID() graphql.ID {
+	return toGraphQLID(r.res.ID)
+}
+
+// Trip resolves the trip the reservation was made on. It is loaded with the
+// other trips of the query.
+func (r *reservationResolver) Trip(ctx context.Context) (*tripResolver, error) {
+	t, err := loadTrip(ctx, r.res.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tripResolver{t, r.service}, nil
+}
+
+func (r *reservationResolver) UserID() graphql.ID {
+	return toGraphQLID(r.res.UserID)
+}
+
+// Source resolves the stop the passenger is picked up at.
+func (r *reservationResolver) Source(ctx context.Context) (*stopResolver, error) {
+	return r.stop(ctx, r.res.SourceID)
+}
+
+// Destination resolves the stop the passenger is dropped off at.
+func (r *reservationResolver) Destination(ctx context.Context) (*stopResolver, error) {
+	return r.stop(ctx, r.res.DestinationID)
+}
+
+func (r *reservationResolver) stop(ctx context.Context, ID entity.ID) (*stopResolver, error) {
+	t, err := loadTrip(ctx, r.res.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := t.FindStop(ID)
+	if s == nil {
+		return nil, nil
+	}
+
+	return &stopResolver{s}, nil
+}
+
+func (r *reservationResolver) Seats() int32 {
+	return int32(r.res.Seats)
+}
+
+func (r *reservationResolver) Fare() *fareResolver {
+	if r.res.Fare == nil {
+		return nil
+	}
+
+	return &fareResolver{r.res.Fare}
+}
+
+func (r *reservationResolver) Payment() *paymentResolver {
+	if r.res.Payment == nil {
+		return nil
+	}
+
+	return &paymentResolver{r.res.Payment}
+}
+
+func (r *reservationResolver) Status() string {
+	return string(r.res.Status)
+}
+
+func (r *reservationResolver) Cancellation() *cancellationResolver {
+	if r.res.Cancellation == nil {
+		return nil
+	}
+
+	return &cancellationResolver{r.res.Cancellation}
+}
+
+func (r *reservationResolver) CheckIn() *checkInResolver {
+	if r.res.CheckIn == nil {
+		return nil
+	}
+
+	return &checkInResolver{r.res.CheckIn}
+}
+
+func (r *reservationResolver) Version() int32 {
+	return int32(r.res.Version)
+}
+
+func (r *reservationResolver) HoldID() *graphql.ID {
+	if r.res.HoldID.IsZero() {
+		return nil
+	}
+
+	ID := toGraphQLID(r.res.HoldID)
+	return &ID
+}
+
+// A tripResolver resolves the fields of a trip.
+type tripResolver struct {
+	t       *entity.Trip
+	service reservation.UseCase
+}
+
+func (r *tripResolver) ID() graphql.ID {
+	return toGraphQLID(r.t.ID)
+}
+
+func (r *tripResolver) DriverID() graphql.ID {
+	return toGraphQLID(r.t.DriverID)
+}
+
+func (r *tripResolver) LeaveAt() graphql.Time {
+	return graphql.Time{Time: r.t.LeaveAt}
+}
+
+func (r *tripResolver) ArriveBy() graphql.Time {
+	return graphql.Time{Time: r.t.ArriveBy}
+}
+
+func (r *tripResolver) Seats() int32 {
+	return int32(r.t.Seats)
+}
+
+func (r *tripResolver) Stops() []*stopResolver {
+	resolvers := make([]*stopResolver, 0, len(r.t.Stops))
+	for _, s := range r.t.Stops {
+		resolvers = append(resolvers, &stopResolver{s})
+	}
+
+	return resolvers
+}
+
+func (r *tripResolver) PricePerSeat() int32 {
+	return int32(r.t.PricePerSeat)
+}
+
+func (r *tripResolver) Currency() *string {
+	return optionalString(r.t.Currency)
+}
+
+// Reservations resolves the reservations made on the trip.
+func (r *tripResolver) Reservations(ctx context.Context) ([]*reservationResolver, error) {
+	return findTripReservations(ctx, r.service, r.t.ID)
+}
+
+// A stopResolver resolves the fields of a trip's stop.
+type stopResolver struct {
+	s *entity.Stop
+}
+
+func (r *stopResolver) ID() graphql.ID {
+	return toGraphQLID(r.s.ID)
+}
+
+func (r *stopResolver) Point() *pointResolver {
+	if r.s.Point == nil {
+		return nil
+	}
+
+	return &pointResolver{r.s.Point}
+}
+
+func (r *stopResolver) Seats() int32 {
+	return int32(r.s.Seats)
+}
+
+func (r *stopResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: r.s.Timestamp}
+}
+
+// A pointResolver resolves the fields of a geographical position.
+type pointResolver struct {
+	p *entity.Point
+}
+
+func (r *pointResolver) Latitude() float64 {
+	return r.p.Latitude
+}
+
+func (r *pointResolver) Longitude() float64 {
+	return r.p.Longitude
+}
+
+// A fareResolver resolves the fields of a reservation's fare.
+type fareResolver struct {
+	f *entity.Fare
+}
+
+func (r *fareResolver) Amount() int32 {
+	return int32(r.f.Amount)
+}
+
+func (r *fareResolver) Currency() string {
+	return r.f.Currency
+}
+
+// A paymentResolver resolves the fields of a reservation's payment.
+type paymentResolver struct {
+	p *entity.Payment
+}
+
+func (r *paymentResolver) Status() string {
+	return string(r.p.Status)
+}
+
+func (r *paymentResolver) Captured() int32 {
+	return int32(r.p.Captured)
+}
+
+func (r *paymentResolver) Refunded() int32 {
+	return int32(r.p.Refunded)
+}
+
+// A cancellationResolver resolves the fields of a reservation's cancellation.
+type cancellationResolver struct {
+	c *entity.Cancellation
+}
+
+func (r *cancellationResolver) Rule() string {
+	return r.c.Rule
+}
+
+func (r *cancellationResolver) Fee() int32 {
+	return int32(r.c.Fee)
+}
+
+func (r *cancellationResolver) Reason() *string {
+	return optionalString(r.c.Reason)
+}
+
+func (r *cancellationResolver) CancelledAt() graphql.Time {
+	return graphql.Time{Time: r.c.CancelledAt}
+}
+
+// A checkInResolver resolves the fields of a passenger's check-in.
+type checkInResolver struct {
+	c *entity.CheckIn
+}
+
+func (r *checkInResolver) Method() string {
+	return string(r.c.Method)
+}
+
+func (r *checkInResolver) Point() *pointResolver {
+	if r.c.Point == nil {
+		return nil
+	}
+
+	return &pointResolver{r.c.Point}
+}
+
+func (r *checkInResolver) CheckedInAt() graphql.Time {
+	return graphql.Time{Time: r.c.CheckedInAt}
+}
+
+// findTripReservations resolves the reservations made on the trip with the
+// given ID.
+func findTripReservations(ctx context.Context, service reservation.UseCase, tripID entity.ID) ([]*reservationResolver, error) {
+	reservations, err := service.FindByTripID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*reservationResolver, 0, len(reservations))
+	for _, res := range reservations {
+		resolvers = append(resolvers, &reservationResolver{res, service})
+	}
+
+	return resolvers, nil
+}
+
+func toID(ID graphql.ID) entity.ID {
+	return entity.NewIDFromHex(string(ID))
+}
+
+func toGraphQLID(ID entity.ID) graphql.ID {
+	return graphql.ID(ID.Hex())
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
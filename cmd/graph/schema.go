@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"context"
+
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// MaxDepth represents the maximum depth of the selections of a query.
+const MaxDepth = 8
+
+// MaxParallelism represents the maximum number of fields of a query that are
+// resolved at the same time. The trips of the fields resolved at the same time
+// are loaded in a single batch.
+const MaxParallelism = 50
+
+// schemaDefinition describes the types of the GraphQL API and the queries it
+// supports.
+const schemaDefinition = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# The reservation with the given ID. Only its passenger and the driver of its
+	# trip can retrieve it.
+	reservation(id: ID!): Reservation
+
+	# The reservations with the given IDs, in the same order. The query fails if
+	# any of them cannot be retrieved, or if the user is neither the passenger
+	# nor the driver of one of them.
+	reservations(ids: [ID!]!): [Reservation!]!
+
+	# The reservations made on a trip. Only the trip's driver can list them.
+	tripReservations(tripId: ID!): [Reservation!]!
+}
+
+# A Reservation holds seats on a trip, from a source stop to a destination
+# stop.
+type Reservation {
+	id: ID!
+	trip: Trip
+	userId: ID!
+	source: Stop
+	destination: Stop
+	seats: Int!
+	fare: Fare
+	payment: Payment
+
+	# One of pending, confirmed, completed, rejected, cancelled,
+	# cancelled-by-driver, no-show or expired.
+	status: String!
+
+	cancellation: Cancellation
+	checkIn: CheckIn
+	version: Int!
+	holdId: ID
+}
+
+# A Trip is a ride offered by a driver, as exposed by the trip-service.
+type Trip {
+	id: ID!
+	driverId: ID!
+	leaveAt: Time!
+	arriveBy: Time!
+	seats: Int!
+	stops: [Stop!]!
+	pricePerSeat: Int!
+	currency: String
+
+	# The reservations made on the trip. Only the trip's driver can list them.
+	reservations: [Reservation!]!
+}
+
+# A Stop is a place where a trip picks up or drops off passengers.
+type Stop {
+	id: ID!
+	point: Point
+	seats: Int!
+	timestamp: Time!
+}
+
+# A Point is a geographical position.
+type Point {
+	latitude: Float!
+	longitude: Float!
+}
+
+# A Fare is the price of a reservation, in the currency's smallest unit (ex.
+# cents).
+type Fare {
+	amount: Int!
+	currency: String!
+}
+
+# A Payment is the state of the payment of a reservation's fare.
+type Payment {
+	# One of authorized, captured, voided or refunded.
+	status: String!
+
+	captured: Int!
+	refunded: Int!
+}
+
+# A Cancellation contains the details of a reservation's cancellation.
+type Cancellation {
+	# The cancellation policy rule that was applied.
+	rule: String!
+
+	fee: Int!
+	reason: String
+	cancelledAt: Time!
+}
+
+# A CheckIn contains the details of a passenger's check-in.
+type CheckIn {
+	# One of scan or self.
+	method: String!
+
+	point: Point
+	checkedInAt: Time!
+}
+`
+
+// A Schema executes GraphQL queries on reservations and their trips. It uses
+// the same use cases as the REST handlers, so both APIs behave the same way.
+type Schema struct {
+	schema      *graphql.Schema
+	tripService trip.UseCase
+}
+
+// NewSchema creates the schema of the GraphQL API, whose reservations are
+// resolved by the reservation service and trips by the trip service.
+func NewSchema(reservationService reservation.UseCase, tripService trip.UseCase) (*Schema, error) {
+	schema, err := graphql.ParseSchema(
+		schemaDefinition,
+		&queryResolver{reservationService},
+		graphql.MaxDepth(MaxDepth),
+		graphql.MaxParallelism(MaxParallelism),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{schema, tripService}, nil
+}
+
+// Exec executes a query. The trips the query needs are loaded in batches, and
+// each trip is only loaded once per query.
+func (s *Schema) Exec(ctx context.Context, query string, operationName string, variables map[string]interface{}) *graphql.Response {
+	ctx = withTripLoader(ctx, newTripLoader(s.tripService))
+
+	return s.schema.Exec(ctx, query, operationName, variables)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+)
+
+// A batchResult is the outcome of the operation performed on one of the
+// reservations of a batch.
+type batchResult struct {
+	Status      int                 `json:"status"`
+	Reservation *entity.Reservation `json:"reservation,omitempty"`
+	Error       *Error              `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Results []*batchResult `json:"results"`
+}
+
+type batchCreateRequest struct {
+	Reservations []*entity.Reservation `json:"reservations"`
+}
+
+type batchCancelRequest struct {
+	IDs []entity.ID `json:"ids"`
+}
+
+// CreateReservationBatch handles a request to create several reservations at
+// once, all or nothing. The response contains the outcome for each
+// reservation, in the same order as the request.
+func CreateReservationBatch(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req batchCreateRequest
+		err := decodeBody(r, &req)
+		if err != nil {
+			return err
+		}
+
+		rs, err := service.RegisterBatch(r.Context(), req.Reservations)
+		if batchErr, ok := err.(reservation.BatchError); ok {
+			return writeBatchError(w, r, batchErr, nil, NewError(
+				CodeBatchItemSkipped,
+				"reservation was not registered because another reservation of the batch failed",
+				nil,
+			))
+		} else if err != nil {
+			return err
+		}
+
+		results := make([]*batchResult, len(rs))
+		for i, res := range rs {
+			results[i] = &batchResult{Status: http.StatusCreated, Reservation: res}
+		}
+
+		return writeBatch(w, http.StatusCreated, results)
+	}
+}
+
+// DeleteReservationBatch handles a request to cancel several reservations at
+// once. The response contains the outcome for each reservation, in the same
+// order as the request.
+func DeleteReservationBatch(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req batchCancelRequest
+		err := decodeBody(r, &req)
+		if err != nil {
+			return err
+		}
+
+		rs, err := service.DeleteBatch(r.Context(), req.IDs)
+		if batchErr, ok := err.(reservation.BatchError); ok {
+			return writeBatchError(w, r, batchErr, rs, NewError(
+				CodeBatchItemSkipped,
+				"reservation was not cancelled because another reservation of the batch is invalid",
+				nil,
+			))
+		} else if err != nil {
+			return err
+		}
+
+		results := make([]*batchResult, len(rs))
+		for i, res := range rs {
+			results[i] = &batchResult{Status: http.StatusOK, Reservation: res}
+		}
+
+		return writeBatch(w, http.StatusOK, results)
+	}
+}
+
+// writeBatchError writes the outcome of a batch that failed. The response's
+// status code is the one of the first reservation that failed. Reservations
+// that did not fail are either reported as successful, when they are present
+// in the given reservations, or as skipped with the given error.
+func writeBatchError(w http.ResponseWriter, r *http.Request, batchErr reservation.BatchError, rs []*entity.Reservation, skipped *Error) error {
+	requestID, _ := requestid.FromContext(r.Context())
+
+	status := 0
+	results := make([]*batchResult, len(batchErr.Errs))
+	for i, err := range batchErr.Errs {
+		switch {
+		case err != nil:
+			handlerErr := WrapError(err)
+			log.Printf("[Request ID=%s] error: batch item %d: %s", requestID, i, handlerErr)
+
+			results[i] = &batchResult{Status: handlerErr.Status, Error: handlerErr}
+			if status == 0 {
+				status = handlerErr.Status
+			}
+		case i < len(rs) && rs[i] != nil:
+			results[i] = &batchResult{Status: http.StatusOK, Reservation: rs[i]}
+		default:
+			results[i] = &batchResult{Status: skipped.Status, Error: skipped}
+		}
+	}
+
+	return writeBatch(w, status, results)
+}
+
+func writeBatch(w http.ResponseWriter, status int, results []*batchResult) error {
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(batchResponse{results})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
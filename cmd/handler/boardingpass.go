@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"azure.com/ecovo/reservation-service/pkg/boardingpass"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"github.com/gorilla/mux"
+)
+
+// GetBoardingPass handles a request to retrieve the boarding pass of a
+// reservation. The QR code is returned as a PNG image when the client prefers
+// it, and the whole boarding pass is returned as JSON otherwise.
+func GetBoardingPass(service boardingpass.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+
+		mediaType, err := negotiate(r, "application/json", "image/png")
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			return err
+		}
+
+		pass, err := service.Issue(r.Context(), id)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			return err
+		}
+
+		if mediaType == "image/png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.WriteHeader(http.StatusOK)
+
+			_, err = w.Write(pass.QRCode)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(pass)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// A boardingPassToken is the body of a request to verify a boarding pass.
+type boardingPassToken struct {
+	Token string `json:"token"`
+}
+
+// VerifyBoardingPass handles a request to verify a boarding pass's token.
+func VerifyBoardingPass(service boardingpass.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var t boardingPassToken
+		err := decodeBody(r, &t)
+		if err != nil {
+			return err
+		}
+
+		v, err := service.Verify(r.Context(), t.Token)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(v)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetBoardingPassKeys handles a request to retrieve the public key that can be
+// used to verify boarding passes offline.
+func GetBoardingPassKeys(service boardingpass.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		err := json.NewEncoder(w).Encode(service.KeySet())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
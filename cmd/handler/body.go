@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MaxBodySize represents the maximum size of a request's body, in bytes, once
+// it is decompressed.
+const MaxBodySize = 1 << 20
+
+// readBody reads the body of a request, decompressing it according to its
+// Content-Encoding header. A body that is not empty must be JSON encoded in
+// UTF-8, and must not be larger than MaxBodySize once decompressed.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(body, MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > MaxBodySize {
+		return nil, BodyTooLargeError{fmt.Sprintf("request body must not be larger than %d bytes", MaxBodySize)}
+	}
+
+	if len(bytes.TrimSpace(b)) > 0 {
+		err = checkContentType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// decompress returns a reader of the decompressed body of a request.
+func decompress(r *http.Request) (io.Reader, error) {
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return r.Body, nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err == io.EOF {
+			return bytes.NewReader(nil), nil
+		} else if err != nil {
+			return nil, MalformedBodyError{fmt.Sprintf("request body is not valid gzip (%s)", err)}
+		}
+		return zr, nil
+	default:
+		return nil, UnsupportedMediaTypeError{fmt.Sprintf("Content-Encoding \"%s\" is not supported, use gzip or identity", encoding)}
+	}
+}
+
+// checkContentType ensures that the Content-Type header of a request
+// describes a JSON body encoded in UTF-8.
+func checkContentType(contentType string) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "application/json" {
+		return UnsupportedMediaTypeError{fmt.Sprintf("Content-Type \"%s\" is not supported, use application/json", contentType)}
+	}
+
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") && !strings.EqualFold(charset, "utf8") {
+		return UnsupportedMediaTypeError{fmt.Sprintf("charset \"%s\" is not supported, use utf-8", charset)}
+	}
+
+	return nil
+}
+
+// decodeBody decodes the JSON body of a request into v. Fields that v does not
+// have, and anything following the JSON value, are rejected.
+func decodeBody(r *http.Request, v interface{}) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = dec.Token()
+	if err != io.EOF {
+		return MalformedBodyError{"request body must contain a single JSON value"}
+	}
+
+	return nil
+}
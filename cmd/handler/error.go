@@ -1,41 +1,298 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"azure.com/ecovo/reservation-service/cmd/middleware/auth"
+	"azure.com/ecovo/reservation-service/pkg/boardingpass"
+	"azure.com/ecovo/reservation-service/pkg/booking"
+	"azure.com/ecovo/reservation-service/pkg/checkin"
 	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"azure.com/ecovo/reservation-service/pkg/payment"
+	"azure.com/ecovo/reservation-service/pkg/pricing"
 	"azure.com/ecovo/reservation-service/pkg/reservation"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+	"azure.com/ecovo/reservation-service/pkg/webhook"
 )
 
-// An Error is an application error that can be handled by a handler.
+// ProblemContentType represents the media type of the body of a response to a
+// request that failed (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix is prepended to an error code to build the URI that
+// identifies the type of a problem.
+const ProblemTypePrefix = "urn:ecovo:reservation-service:problem:"
+
+// An ErrorCode is a stable, machine-readable identifier of a kind of error.
+// Unlike messages, codes never change, so clients can rely on them.
+type ErrorCode string
+
+// The codes of the errors the service can respond with.
+const (
+	CodeValidationFailed        ErrorCode = "validation-failed"
+	CodeMalformedJSON           ErrorCode = "malformed-json"
+	CodeUnknownField            ErrorCode = "unknown-field"
+	CodeBodyTooLarge            ErrorCode = "body-too-large"
+	CodeUnsupportedMediaType    ErrorCode = "unsupported-media-type"
+	CodeNotAcceptable           ErrorCode = "not-acceptable"
+	CodeInvalidSegment          ErrorCode = "invalid-segment"
+	CodeInvalidBoardingPass     ErrorCode = "invalid-boarding-pass"
+	CodeUnauthorized            ErrorCode = "unauthorized"
+	CodePaymentDeclined         ErrorCode = "payment-declined"
+	CodeForbidden               ErrorCode = "forbidden"
+	CodeReservationNotFound     ErrorCode = "reservation-not-found"
+	CodeHoldNotFound            ErrorCode = "hold-not-found"
+	CodeWebhookNotFound         ErrorCode = "webhook-not-found"
+	CodeTripNotFound            ErrorCode = "trip-not-found"
+	CodeReservationExists       ErrorCode = "reservation-already-exists"
+	CodeConcurrentModification  ErrorCode = "concurrent-modification"
+	CodeInvalidStatus           ErrorCode = "invalid-status"
+	CodeBoardingPassNotIssuable ErrorCode = "boarding-pass-not-issuable"
+	CodeBatchItemSkipped        ErrorCode = "batch-item-skipped"
+	CodePreconditionFailed      ErrorCode = "precondition-failed"
+	CodeCheckInNotAllowed       ErrorCode = "check-in-not-allowed"
+	CodeUserBlocked             ErrorCode = "user-blocked"
+	CodeTooManyActive           ErrorCode = "too-many-active-reservations"
+	CodeTooManyCancellations    ErrorCode = "too-many-cancellations"
+	CodeOverlappingReservation  ErrorCode = "overlapping-reservation"
+	CodeRateLimited             ErrorCode = "rate-limited"
+	CodeInternal                ErrorCode = "internal-error"
+)
+
+// A problem describes a kind of error.
+type problem struct {
+	status int
+	title  string
+}
+
+// problems is the catalogue of the kinds of errors, by code.
+var problems = map[ErrorCode]problem{
+	CodeValidationFailed:        {http.StatusBadRequest, "Request is invalid"},
+	CodeMalformedJSON:           {http.StatusBadRequest, "Request body is not valid JSON"},
+	CodeUnknownField:            {http.StatusBadRequest, "Request body contains an unknown field"},
+	CodeBodyTooLarge:            {http.StatusRequestEntityTooLarge, "Request body is too large"},
+	CodeUnsupportedMediaType:    {http.StatusUnsupportedMediaType, "Request body's media type is not supported"},
+	CodeNotAcceptable:           {http.StatusNotAcceptable, "None of the accepted media types are available"},
+	CodeInvalidSegment:          {http.StatusBadRequest, "Stops are not part of the trip"},
+	CodeInvalidBoardingPass:     {http.StatusBadRequest, "Boarding pass is invalid"},
+	CodeUnauthorized:            {http.StatusUnauthorized, "Unauthorized"},
+	CodePaymentDeclined:         {http.StatusPaymentRequired, "Payment was declined"},
+	CodeForbidden:               {http.StatusForbidden, "Forbidden"},
+	CodeReservationNotFound:     {http.StatusNotFound, "Reservation does not exist"},
+	CodeHoldNotFound:            {http.StatusNotFound, "Hold does not exist"},
+	CodeWebhookNotFound:         {http.StatusNotFound, "Webhook does not exist"},
+	CodeTripNotFound:            {http.StatusNotFound, "Trip does not exist"},
+	CodeReservationExists:       {http.StatusConflict, "Reservation already exists"},
+	CodeConcurrentModification:  {http.StatusConflict, "Reservation was modified concurrently"},
+	CodeInvalidStatus:           {http.StatusConflict, "Reservation's status does not allow it"},
+	CodeBoardingPassNotIssuable: {http.StatusConflict, "Boarding pass cannot be issued"},
+	CodeBatchItemSkipped:        {http.StatusFailedDependency, "Another item of the batch failed"},
+	CodePreconditionFailed:      {http.StatusPreconditionFailed, "Precondition failed"},
+	CodeCheckInNotAllowed:       {http.StatusUnprocessableEntity, "Check-in is not allowed"},
+	CodeUserBlocked:             {http.StatusUnprocessableEntity, "User is not allowed to book"},
+	CodeTooManyActive:           {http.StatusUnprocessableEntity, "User holds too many active reservations"},
+	CodeTooManyCancellations:    {http.StatusUnprocessableEntity, "User cancelled too many reservations"},
+	CodeOverlappingReservation:  {http.StatusUnprocessableEntity, "Reservation overlaps with another one"},
+	CodeRateLimited:             {http.StatusTooManyRequests, "Too many requests"},
+	CodeInternal:                {http.StatusInternalServerError, "Internal server error"},
+}
+
+// ErrorCodes returns the codes of every kind of error.
+func ErrorCodes() []ErrorCode {
+	codes := make([]ErrorCode, 0, len(problems))
+	for code := range problems {
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+// An Error is an application error that can be handled by a handler. It is
+// sent to the client as the details of a problem (RFC 7807).
 type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Error   error  `json:"-"`
+	Type          string                 `json:"type"`
+	Title         string                 `json:"title"`
+	Status        int                    `json:"status"`
+	Detail        string                 `json:"detail"`
+	Instance      string                 `json:"instance,omitempty"`
+	Code          ErrorCode              `json:"code"`
+	InvalidParams []*entity.InvalidParam `json:"invalid-params,omitempty"`
+	Error         error                  `json:"-"`
+}
+
+// NewError creates an application error of the kind with the given code.
+func NewError(code ErrorCode, detail string, err error) *Error {
+	p, ok := problems[code]
+	if !ok {
+		code = CodeInternal
+		p = problems[code]
+	}
+
+	return &Error{
+		Type:   ProblemTypePrefix + string(code),
+		Title:  p.title,
+		Status: p.status,
+		Detail: detail,
+		Code:   code,
+		Error:  err,
+	}
 }
 
 func (err Error) String() string {
-	return fmt.Sprintf("code=%d, message=\"%s\", error=\"%s\"", err.Code, err.Message, err.Error)
+	return fmt.Sprintf("status=%d, code=%s, detail=\"%s\", error=\"%s\"", err.Status, err.Code, err.Detail, err.Error)
+}
+
+// A PreconditionFailedError is an error that occurs when a request's
+// conditional headers, such as If-Match, do not match the resource's current
+// state.
+type PreconditionFailedError struct {
+	msg string
+}
+
+func (e PreconditionFailedError) Error() string {
+	return e.msg
 }
 
+// An UnsupportedMediaTypeError is an error that occurs when a request's body
+// is not JSON, or is encoded in a way that is not supported.
+type UnsupportedMediaTypeError struct {
+	msg string
+}
+
+func (e UnsupportedMediaTypeError) Error() string {
+	return e.msg
+}
+
+// A NotAcceptableError is an error that occurs when a request's Accept header
+// does not accept any of the media types a response can have.
+type NotAcceptableError struct {
+	msg string
+}
+
+func (e NotAcceptableError) Error() string {
+	return e.msg
+}
+
+// A BodyTooLargeError is an error that occurs when a request's body is larger
+// than the maximum size.
+type BodyTooLargeError struct {
+	msg string
+}
+
+func (e BodyTooLargeError) Error() string {
+	return e.msg
+}
+
+// A MalformedBodyError is an error that occurs when a request's body cannot
+// be decoded.
+type MalformedBodyError struct {
+	msg string
+}
+
+func (e MalformedBodyError) Error() string {
+	return e.msg
+}
+
+// A TooManyRequestsError is an error that occurs when a client makes more
+// requests on a route than its rate limit allows.
+type TooManyRequestsError struct {
+	msg string
+}
+
+func (e TooManyRequestsError) Error() string {
+	return e.msg
+}
+
+// unknownFieldPrefix is the beginning of the message of the error a JSON
+// decoder returns when it finds a field it does not know about.
+const unknownFieldPrefix = "json: unknown field "
+
 // WrapError wraps the given error in an application error that can be handled
 // by a handler.
 func WrapError(err error) *Error {
 	if err == nil {
 		return nil
 	} else if _, ok := err.(auth.UnauthorizedError); ok {
-		return &Error{http.StatusUnauthorized, "unauthorized", err}
+		return NewError(CodeUnauthorized, "unauthorized", err)
+	} else if _, ok := err.(reservation.ForbiddenError); ok {
+		return NewError(CodeForbidden, "you are not allowed to manage this reservation", err)
+	} else if _, ok := err.(hold.ForbiddenError); ok {
+		return NewError(CodeForbidden, "you are not allowed to manage this hold", err)
 	} else if _, ok := err.(reservation.NotFoundError); ok {
-		return &Error{http.StatusNotFound, "reservation does not exist", err}
-	} else if _, ok := err.(entity.ValidationError); ok {
-		return &Error{http.StatusBadRequest, err.Error(), err}
+		return NewError(CodeReservationNotFound, "reservation does not exist", err)
+	} else if _, ok := err.(hold.NotFoundError); ok {
+		return NewError(CodeHoldNotFound, "hold does not exist or has expired", err)
+	} else if _, ok := err.(webhook.NotFoundError); ok {
+		return NewError(CodeWebhookNotFound, "webhook does not exist", err)
+	} else if _, ok := err.(reservation.AlreadyExistsError); ok {
+		return NewError(CodeReservationExists, "reservation already exists", err)
+	} else if _, ok := err.(reservation.ConflictError); ok {
+		return NewError(CodeConcurrentModification, "reservation was modified concurrently, please try again", err)
+	} else if _, ok := err.(PreconditionFailedError); ok {
+		return NewError(CodePreconditionFailed, "reservation was modified since it was retrieved", err)
+	} else if _, ok := err.(reservation.PreconditionFailedError); ok {
+		return NewError(CodePreconditionFailed, "reservation was modified since it was retrieved", err)
+	} else if _, ok := err.(reservation.InvalidStatusError); ok {
+		return NewError(CodeInvalidStatus, err.Error(), err)
+	} else if _, ok := err.(boardingpass.NotIssuableError); ok {
+		return NewError(CodeBoardingPassNotIssuable, "boarding passes are only issued for confirmed reservations", err)
+	} else if _, ok := err.(trip.NotFoundError); ok {
+		return NewError(CodeTripNotFound, "trip does not exist", err)
+	} else if _, ok := err.(checkin.NotAllowedError); ok {
+		return NewError(CodeCheckInNotAllowed, err.Error(), err)
+	} else if _, ok := err.(booking.BlockedError); ok {
+		return NewError(CodeUserBlocked, "you are not allowed to book reservations", err)
+	} else if _, ok := err.(booking.TooManyActiveError); ok {
+		return NewError(CodeTooManyActive, err.Error(), err)
+	} else if _, ok := err.(booking.TooManyCancellationsError); ok {
+		return NewError(CodeTooManyCancellations, err.Error(), err)
+	} else if _, ok := err.(booking.OverlapError); ok {
+		return NewError(CodeOverlappingReservation, err.Error(), err)
+	} else if _, ok := err.(payment.DeclinedError); ok {
+		return NewError(CodePaymentDeclined, "payment was declined", err)
+	} else if _, ok := err.(pricing.InvalidSegmentError); ok {
+		return NewError(CodeInvalidSegment, err.Error(), err)
+	} else if _, ok := err.(boardingpass.InvalidTokenError); ok {
+		return NewError(CodeInvalidBoardingPass, "boarding pass is invalid", err)
+	} else if validationErr, ok := err.(entity.ValidationError); ok {
+		handlerErr := NewError(CodeValidationFailed, err.Error(), err)
+		handlerErr.InvalidParams = validationErr.InvalidParams()
+		return handlerErr
+	} else if _, ok := err.(UnsupportedMediaTypeError); ok {
+		return NewError(CodeUnsupportedMediaType, err.Error(), err)
+	} else if _, ok := err.(NotAcceptableError); ok {
+		return NewError(CodeNotAcceptable, err.Error(), err)
+	} else if _, ok := err.(TooManyRequestsError); ok {
+		return NewError(CodeRateLimited, err.Error(), err)
+	} else if _, ok := err.(BodyTooLargeError); ok {
+		return NewError(CodeBodyTooLarge, err.Error(), err)
+	} else if _, ok := err.(MalformedBodyError); ok {
+		return NewError(CodeMalformedJSON, err.Error(), err)
+	} else if err == io.EOF {
+		return NewError(CodeMalformedJSON, "request body is missing", err)
+	} else if err == io.ErrUnexpectedEOF {
+		return NewError(CodeMalformedJSON, "request body ends unexpectedly", err)
+	} else if _, ok := err.(*json.SyntaxError); ok {
+		return NewError(CodeMalformedJSON, fmt.Sprintf("request body is not valid JSON (%s)", err), err)
+	} else if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		handlerErr := NewError(CodeValidationFailed, fmt.Sprintf("%s cannot be a JSON %s", typeErr.Field, typeErr.Value), err)
+		handlerErr.InvalidParams = []*entity.InvalidParam{{Name: typeErr.Field, Reason: fmt.Sprintf("cannot be a JSON %s", typeErr.Value)}}
+		return handlerErr
+	} else if strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+		name := strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`)
+		handlerErr := NewError(CodeUnknownField, fmt.Sprintf("%s is not a known field", name), err)
+		handlerErr.InvalidParams = []*entity.InvalidParam{{Name: name, Reason: "is not a known field"}}
+		return handlerErr
 	} else {
-		return &Error{
-			http.StatusInternalServerError,
+		return NewError(
+			CodeInternal,
 			"Something went wrong while processing your request. Please contact your system administrator.",
 			err,
-		}
+		)
 	}
 }
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+)
+
+// ETag returns the entity tag of a reservation, which is derived from its
+// version.
+func ETag(res *entity.Reservation) string {
+	return `"` + strconv.Itoa(res.Version) + `"`
+}
+
+// setETag sets the ETag header of the response to the reservation's entity
+// tag.
+func setETag(w http.ResponseWriter, res *entity.Reservation) {
+	w.Header().Set("ETag", ETag(res))
+}
+
+// checkIfMatch ensures that the reservation's entity tag matches one of the
+// entity tags in the request's If-Match header, if it is present, and returns
+// the version it matched, or zero when any version matches. Weak entity tags
+// never match.
+//
+// The version must then be passed along to the use case, which only modifies
+// the reservation if it still has this version.
+func checkIfMatch(r *http.Request, res *entity.Reservation) (int, error) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return 0, nil
+	}
+
+	etag := ETag(res)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return 0, nil
+		} else if tag == etag {
+			return res.Version, nil
+		}
+	}
+
+	return 0, PreconditionFailedError{fmt.Sprintf("handler: reservation with ID \"%s\" does not match %s (current ETag is %s)", res.ID, header, etag)}
+}
+
+// matchedVersion retrieves the reservation with the given ID for the
+// authenticated user when the request has an If-Match header, and returns the
+// version it matched, like checkIfMatch. It returns zero without retrieving
+// the reservation when the header is missing.
+func matchedVersion(r *http.Request, service reservation.UseCase, ID entity.ID) (int, error) {
+	if r.Header.Get("If-Match") == "" {
+		return 0, nil
+	}
+
+	res, err := service.FindForUser(r.Context(), ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return checkIfMatch(r, res)
+}
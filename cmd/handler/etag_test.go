@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+)
+
+func TestETag(t *testing.T) {
+	etag := ETag(&entity.Reservation{Version: 3})
+	if etag != `"3"` {
+		t.Errorf("got %s, want \"3\"", etag)
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	res := &entity.Reservation{ID: "reservation", Version: 2}
+
+	tests := []struct {
+		name    string
+		header  string
+		version int
+		matches bool
+	}{
+		{"no header", "", 0, true},
+		{"matching", `"2"`, 2, true},
+		{"one of several", `"1", "2"`, 2, true},
+		{"any", `*`, 0, true},
+		{"other version", `"1"`, 0, false},
+		{"weak", `W/"2"`, 0, false},
+		{"unquoted", `2`, 0, false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("DELETE", "/reservations/reservation", nil)
+		if tt.header != "" {
+			r.Header.Set("If-Match", tt.header)
+		}
+
+		version, err := checkIfMatch(r, res)
+		if tt.matches {
+			if err != nil || version != tt.version {
+				t.Errorf("%s: got version %d (%v), want %d", tt.name, version, err, tt.version)
+			}
+		} else if _, ok := err.(PreconditionFailedError); !ok {
+			t.Errorf("%s: got error %v, want a PreconditionFailedError", tt.name, err)
+		}
+	}
+}
+
+// A versionedService is a reservation use case that only retrieves a
+// reservation with a given version.
+type versionedService struct {
+	reservation.UseCase
+	version int
+}
+
+func (s versionedService) FindForUser(ctx context.Context, ID entity.ID) (*entity.Reservation, error) {
+	return &entity.Reservation{ID: ID, Version: s.version}, nil
+}
+
+func TestMatchedVersion(t *testing.T) {
+	service := versionedService{version: 2}
+
+	r := httptest.NewRequest("POST", "/trips/trip/reservations/reservation/accept", nil)
+	version, err := matchedVersion(r, service, "reservation")
+	if err != nil || version != 0 {
+		t.Errorf("no header: got version %d (%v), want 0", version, err)
+	}
+
+	r.Header.Set("If-Match", `"2"`)
+	version, err = matchedVersion(r, service, "reservation")
+	if err != nil || version != 2 {
+		t.Errorf("matching header: got version %d (%v), want 2", version, err)
+	}
+
+	r.Header.Set("If-Match", `"1"`)
+	_, err = matchedVersion(r, service, "reservation")
+	if _, ok := err.(PreconditionFailedError); !ok {
+		t.Errorf("other version: got error %v, want a PreconditionFailedError", err)
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	"github.com/gorilla/mux"
+)
+
+// EventStreamContentType represents the media type of a stream of
+// server-sent events.
+const EventStreamContentType = "text/event-stream"
+
+// KeepAliveInterval represents how often a comment is sent on an idle event
+// stream, so that proxies do not close the connection.
+const KeepAliveInterval = 15 * time.Second
+
+// WatchReservations handles a request to stream the changes made to the
+// authenticated user's reservations as server-sent events.
+func WatchReservations(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		sub, err := service.WatchUser(r.Context())
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			return err
+		}
+
+		return streamChanges(w, r, sub)
+	}
+}
+
+// WatchTripReservations handles a request, sent by the driver of a trip, to
+// stream the changes made to the trip's roster as server-sent events.
+func WatchTripReservations(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+
+		sub, err := service.WatchTrip(r.Context(), tripID)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			return err
+		}
+
+		return streamChanges(w, r, sub)
+	}
+}
+
+// streamChanges writes every change received by the subscription as a
+// server-sent event, until the client goes away or the subscription is
+// closed. The event's name is the type of the change, its ID is made of the
+// reservation's ID and version, and its data is the reservation.
+func streamChanges(w http.ResponseWriter, r *http.Request, sub *reservation.Subscription) error {
+	defer sub.Close()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		return fmt.Errorf("handler: response writer does not support streaming")
+	}
+
+	w.Header().Set("Content-Type", EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(KeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case c, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+
+			data, err := json.Marshal(c.Reservation)
+			if err != nil {
+				return nil
+			}
+
+			_, err = fmt.Fprintf(w, "event: %s\nid: %s:%d\ndata: %s\n\n", c.Type, c.Reservation.ID, c.Reservation.Version, data)
+			if err != nil {
+				return nil
+			}
+		case <-ticker.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return nil
+			}
+		}
+
+		flusher.Flush()
+	}
+}
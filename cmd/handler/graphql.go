@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"azure.com/ecovo/reservation-service/cmd/graph"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// A graphQLRequest is the body of a request to execute a GraphQL query.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// ExecuteGraphQL handles a request to execute a GraphQL query. Like any
+// GraphQL server, it responds with 200 OK even when some fields cannot be
+// resolved; the errors of those fields have the same code as the REST API's
+// in their extensions.
+func ExecuteGraphQL(schema *graph.Schema) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req graphQLRequest
+		err := decodeBody(r, &req)
+		if err != nil {
+			return err
+		}
+
+		resp := schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+		wrapGraphQLErrors(r, resp)
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(resp)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// wrapGraphQLErrors replaces the message of the errors returned while
+// resolving the fields of a query with the detail of the corresponding
+// application error, and adds its code and status to the error's extensions.
+// Errors in the query itself are left as is.
+func wrapGraphQLErrors(r *http.Request, resp *graphql.Response) {
+	for _, queryErr := range resp.Errors {
+		if queryErr.ResolverError == nil {
+			continue
+		}
+
+		handlerErr := WrapError(queryErr.ResolverError)
+
+		requestID, _ := requestid.FromContext(r.Context())
+		log.Printf("[Request ID=%s] error: %s", requestID, handlerErr)
+
+		queryErr.Message = handlerErr.Detail
+		queryErr.Extensions = map[string]interface{}{
+			"code":   handlerErr.Code,
+			"status": handlerErr.Status,
+		}
+		if len(handlerErr.InvalidParams) > 0 {
+			queryErr.Extensions["invalid-params"] = handlerErr.InvalidParams
+		}
+	}
+}
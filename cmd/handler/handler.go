@@ -6,9 +6,16 @@ import (
 	"log"
 	"net/http"
 
-	"azure.com/ecovo/reservation-service/cmd/middleware/requestid"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
 )
 
+// An errorResponse is the body of a response to a request that failed. It
+// contains the details of the problem (RFC 7807).
+type errorResponse struct {
+	*Error
+	RequestID string `json:"requestId"`
+}
+
 // A Handler represents a handler that can return an error.
 type Handler func(http.ResponseWriter, *http.Request) error
 
@@ -19,12 +26,10 @@ func (handler Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		log.Printf("[Request ID=%s] error: %s", requestID, handlerErr)
 
-		type errorResponse struct {
-			*Error
-			RequestID string `json:"requestId"`
-		}
+		handlerErr.Instance = r.URL.RequestURI()
 
-		w.WriteHeader(handlerErr.Code)
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(handlerErr.Status)
 		err := json.NewEncoder(w).Encode(errorResponse{
 			handlerErr,
 			requestID,
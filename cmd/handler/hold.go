@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"github.com/gorilla/mux"
+)
+
+// CreateHold handles a request to hold seats on a trip for a short period of
+// time.
+func CreateHold(service hold.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		var h *entity.Hold
+		err := decodeBody(r, &h)
+		if err != nil {
+			return err
+		}
+
+		if h == nil {
+			h = &entity.Hold{}
+		}
+		h.TripID = entity.NewIDFromHex(vars["tripId"])
+
+		h, err = service.Place(r.Context(), h)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusCreated)
+
+		err = json.NewEncoder(w).Encode(h)
+		if err != nil {
+			_ = service.Release(r.Context(), h.TripID, h.ID)
+
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetHoldByID handles a request to retrieve a hold placed on a trip by its
+// unique identifier.
+func GetHoldByID(service hold.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+		id := entity.NewIDFromHex(vars["id"])
+
+		h, err := service.FindByID(r.Context(), tripID, id)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(h)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteHold handles a request to release seats held on a trip before the hold
+// expires.
+func DeleteHold(service hold.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+		id := entity.NewIDFromHex(vars["id"])
+
+		err := service.Release(r.Context(), tripID, id)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		return nil
+	}
+}
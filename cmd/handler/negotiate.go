@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"azure.com/ecovo/reservation-service/cmd/openapi"
+	"github.com/gorilla/mux"
+)
+
+// Negotiate ensures that the client accepts one of the media types the
+// successful responses of a request's route have, according to the OpenAPI
+// document, before handling it. A request that accepts none of them is
+// rejected as not acceptable.
+func Negotiate(doc *openapi.Document, next http.Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return nil
+		}
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		offers := produces(doc.Operation(r.Method, path))
+		if len(offers) > 0 {
+			_, err = negotiate(r, offers...)
+			if err != nil {
+				return err
+			}
+		}
+
+		next.ServeHTTP(w, r)
+
+		return nil
+	}
+}
+
+// produces returns the media types of the successful responses of the
+// operation.
+func produces(op *openapi.Operation) []string {
+	if op == nil {
+		return nil
+	}
+
+	offers := []string{}
+	for status, response := range op.Responses {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+		for mediaType := range response.Content {
+			offers = append(offers, mediaType)
+		}
+	}
+	sort.Strings(offers)
+
+	return offers
+}
+
+// An acceptRange is one of the media ranges of an Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// matches returns how specifically the range matches the media type: 3 for an
+// exact match, 2 for type/*, 1 for */* and 0 when it does not match.
+func (a *acceptRange) matches(mediaType string) int {
+	if a.mediaType == mediaType {
+		return 3
+	} else if a.mediaType == "*/*" {
+		return 1
+	} else if strings.HasSuffix(a.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(a.mediaType, "*")) {
+		return 2
+	}
+
+	return 0
+}
+
+// parseAccept parses the media ranges of an Accept header, in order.
+func parseAccept(accept string) []*acceptRange {
+	ranges := []*acceptRange{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, &acceptRange{mediaType, q})
+	}
+
+	return ranges
+}
+
+// negotiate returns the media type, among the offered ones, that the client
+// prefers according to the request's Accept header. When the client likes
+// several media types as much, the one it lists first is chosen, and the
+// first offer is chosen when the header is missing.
+func negotiate(r *http.Request, offers ...string) (string, error) {
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], nil
+	}
+
+	ranges := parseAccept(accept)
+
+	best, bestQ, bestPosition := "", 0.0, len(ranges)
+	for _, offer := range offers {
+		q, position, specificity := 0.0, len(ranges), 0
+		for i, a := range ranges {
+			if s := a.matches(offer); s > specificity {
+				q, position, specificity = a.q, i, s
+			}
+		}
+
+		if q > bestQ || (q == bestQ && q > 0 && position < bestPosition) {
+			best, bestQ, bestPosition = offer, q, position
+		}
+	}
+
+	if best == "" {
+		return "", NotAcceptableError{fmt.Sprintf("none of the accepted media types are available, use %s", strings.Join(offers, " or "))}
+	}
+
+	return best, nil
+}
@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"azure.com/ecovo/reservation-service/cmd/openapi"
+	"azure.com/ecovo/reservation-service/pkg/boardingpass"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/event"
+	"github.com/gorilla/mux"
+)
+
+// APIVersion represents the version of the API described by the OpenAPI
+// document.
+const APIVersion = "1.0.0"
+
+// An operation contains what is needed to describe a route in the OpenAPI
+// document.
+type operation struct {
+	method   string
+	path     string
+	id       string
+	summary  string
+	tag      string
+	security []string
+	headers  []*openapi.Parameter
+	query    []*openapi.Parameter
+	body     *openapi.Schema
+	status   int
+	response *openapi.Schema
+	png      bool
+	events   bool
+	errors   []int
+
+	// exports and imports describe operations whose response, or body,
+	// contains reservations as CSV or JSON Lines.
+	exports bool
+	imports bool
+}
+
+var pathParameter = regexp.MustCompile(`{([^}]+)}`)
+
+// contentEncoding describes the header of a request whose body is compressed.
+var contentEncoding = &openapi.Parameter{
+	Name:        "Content-Encoding",
+	In:          "header",
+	Description: "gzip when the body is compressed",
+	Schema:      (&openapi.Schema{Type: "string"}).OneOf("gzip", "identity"),
+}
+
+// rateLimitHeaders describe the state of the client's rate limit, which is
+// sent in every response of a rate limited route.
+var rateLimitHeaders = map[string]*openapi.Header{
+	"RateLimit-Limit":     {Description: "Number of requests that can be made at once", Schema: &openapi.Schema{Type: "integer"}},
+	"RateLimit-Remaining": {Description: "Number of requests that can still be made at once", Schema: &openapi.Schema{Type: "integer"}},
+	"RateLimit-Reset":     {Description: "Seconds until every request can be made again", Schema: &openapi.Schema{Type: "integer"}},
+	"RateLimit-Policy":    {Description: "Limit and the seconds it takes to recover from it (ex. 10;w=60)", Schema: &openapi.Schema{Type: "string"}},
+}
+
+// OpenAPI creates the OpenAPI document that describes every route of the
+// service, the schemas of their bodies and the errors they return.
+func OpenAPI() *openapi.Document {
+	doc := openapi.New("Reservation Service", APIVersion)
+	doc.Info.Description = "Books, manages and tracks reservations on Ecovo trips."
+	doc.Components.SecuritySchemes["bearerAuth"] = &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	doc.Components.SecuritySchemes["basicAuth"] = &openapi.SecurityScheme{Type: "http", Scheme: "basic"}
+
+	statuses := []string{
+		string(entity.StatusPending),
+		string(entity.StatusConfirmed),
+		string(entity.StatusCompleted),
+		string(entity.StatusRejected),
+		string(entity.StatusCancelled),
+		string(entity.StatusCancelledByDriver),
+		string(entity.StatusNoShow),
+		string(entity.StatusExpired),
+	}
+	eventTypes := []string{}
+	for _, t := range event.Types {
+		eventTypes = append(eventTypes, string(t))
+	}
+
+	res := openapi.SchemaOf(entity.Reservation{})
+	res.Property("status").OneOf(statuses...)
+	reservation := doc.Define("Reservation", res)
+	reservations := &openapi.Schema{Type: "array", Items: reservation}
+
+	reservationRequest := doc.Define("ReservationRequest", segmentSchema().
+		Require("userId").
+		Only("tripId", "userId", "sourceId", "destinationId", "seats", "holdId"))
+	quoteRequest := doc.Define("QuoteRequest", segmentSchema().
+		Only("tripId", "sourceId", "destinationId", "seats"))
+	fare := doc.Define("Fare", openapi.SchemaOf(entity.Fare{}))
+
+	batchCreate := openapi.SchemaOf(batchCreateRequest{}).Require("reservations")
+	batchCreate.Property("reservations").Items = reservationRequest
+	batchCreateRequest := doc.Define("BatchCreateRequest", batchCreate)
+	batchCancelRequest := doc.Define("BatchCancelRequest", openapi.SchemaOf(batchCancelRequest{}).Require("ids"))
+	batch := doc.Define("BatchResponse", openapi.SchemaOf(batchResponse{}))
+
+	point := openapi.SchemaOf(entity.Point{}).Require("latitude", "longitude")
+	point.Property("latitude").Between(-90, 90)
+	point.Property("longitude").Between(-180, 180)
+	pointRequest := doc.Define("Point", point)
+	decision := doc.Define("DriverDecision", withMinLength(openapi.SchemaOf(driverDecision{}).Require("reason"), "reason"))
+	scan := doc.Define("CheckInScan", withMinLength(openapi.SchemaOf(checkInScan{}).Require("code"), "code"))
+
+	hold := doc.Define("Hold", openapi.SchemaOf(entity.Hold{}))
+	holdRequest := doc.Define("HoldRequest", segmentSchema().
+		Only("userId", "sourceId", "destinationId", "seats"))
+
+	webhook := doc.Define("Webhook", openapi.SchemaOf(entity.Webhook{}))
+	hook := openapi.SchemaOf(entity.Webhook{}).
+		Only("url", "eventTypes", "secret").
+		Require("url", "eventTypes", "secret")
+	hook.Property("eventTypes").Items.OneOf(eventTypes...)
+	webhookRequest := doc.Define("WebhookRequest", withMinLength(hook, "url"))
+	delivery := doc.Define("Delivery", openapi.SchemaOf(entity.Delivery{}))
+	auditEntry := doc.Define("AuditEntry", openapi.SchemaOf(entity.AuditEntry{}))
+	importReport := doc.Define("ImportReport", openapi.SchemaOf(importResult{}))
+	transfer := &openapi.Schema{Type: "string"}
+
+	boardingPass := doc.Define("BoardingPass", openapi.SchemaOf(boardingpass.BoardingPass{}))
+	boardingPassRequest := doc.Define("BoardingPassToken", withMinLength(openapi.SchemaOf(boardingPassToken{}).Require("token"), "token"))
+	verification := doc.Define("Verification", openapi.SchemaOf(boardingpass.Verification{}))
+	keySet := doc.Define("KeySet", openapi.SchemaOf(boardingpass.KeySet{}))
+
+	graphQLRequest := doc.Define("GraphQLRequest", withMinLength(openapi.SchemaOf(graphQLRequest{}).Require("query"), "query"))
+	graphQLResponse := doc.Define("GraphQLResponse", &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"data":   {Type: "object"},
+			"errors": {Type: "array", Items: &openapi.Schema{Type: "object"}},
+		},
+	})
+
+	problem := openapi.SchemaOf(errorResponse{})
+	codes := []string{}
+	for _, code := range ErrorCodes() {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	problem.Property("code").OneOf(codes...)
+	doc.Define("Error", problem)
+
+	userAuth := []string{"bearerAuth", "basicAuth"}
+	serviceAuth := []string{"basicAuth"}
+	ifMatch := &openapi.Parameter{Name: "If-Match", In: "header", Description: "Only modify the reservation if its ETag matches", Schema: &openapi.Schema{Type: "string"}}
+	accept := &openapi.Parameter{Name: "Accept", In: "header", Description: "image/png to prefer the QR code over the whole boarding pass", Schema: &openapi.Schema{Type: "string"}}
+	limit := &openapi.Parameter{Name: "limit", In: "query", Description: "Maximum number of deliveries to retrieve", Schema: &openapi.Schema{Type: "integer"}}
+	exportQuery := []*openapi.Parameter{
+		{Name: "from", In: "query", Description: "Only export the reservations created at or after this date or date and time (RFC 3339)", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "to", In: "query", Description: "Only export the reservations created before this date or date and time (RFC 3339)", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "tripId", In: "query", Description: "Only export the reservations made on this trip", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "userId", In: "query", Description: "Only export the reservations made by this user", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "status", In: "query", Description: "Only export the reservations with this status", Schema: (&openapi.Schema{Type: "string"}).OneOf(statuses...)},
+	}
+	dryRun := &openapi.Parameter{Name: "dryRun", In: "query", Description: "true to only validate the reservations", Schema: &openapi.Schema{Type: "boolean"}}
+
+	operations := []*operation{
+		// Reservations
+		{method: "POST", path: "/reservations", id: "createReservation", summary: "Books a reservation", tag: "Reservations", security: userAuth, body: reservationRequest, status: http.StatusCreated, response: reservation, errors: []int{402, 403, 404, 409, 422}},
+		{method: "POST", path: "/reservations:batch", id: "createReservationBatch", summary: "Books several reservations at once, all or nothing", tag: "Reservations", security: userAuth, body: batchCreateRequest, status: http.StatusCreated, response: batch, errors: []int{424}},
+		{method: "POST", path: "/reservations:batchCancel", id: "cancelReservationBatch", summary: "Cancels several reservations at once", tag: "Reservations", security: userAuth, body: batchCancelRequest, status: http.StatusOK, response: batch, errors: []int{424}},
+		{method: "POST", path: "/reservations/quote", id: "quoteReservation", summary: "Quotes the fare of a reservation without booking it", tag: "Reservations", security: userAuth, body: quoteRequest, status: http.StatusOK, response: fare, errors: []int{404}},
+		{method: "GET", path: "/reservations:watch", id: "watchReservations", summary: "Streams the changes made to the user's reservations as server-sent events", tag: "Reservations", security: userAuth, status: http.StatusOK, response: reservation, events: true, errors: []int{403}},
+		{method: "GET", path: "/reservations:export", id: "exportReservations", summary: "Streams reservations as CSV or JSON Lines", tag: "Administration", security: serviceAuth, query: exportQuery, status: http.StatusOK, response: transfer, exports: true, errors: []int{400}},
+		{method: "POST", path: "/reservations:import", id: "importReservations", summary: "Imports reservations from CSV or JSON Lines", tag: "Administration", security: serviceAuth, query: []*openapi.Parameter{dryRun}, body: transfer, imports: true, status: http.StatusOK, response: importReport},
+		{method: "GET", path: "/reservations/{id}", id: "getReservation", summary: "Retrieves a reservation", tag: "Reservations", security: userAuth, status: http.StatusOK, response: reservation, errors: []int{403, 404}},
+		{method: "DELETE", path: "/reservations/{id}", id: "cancelReservation", summary: "Cancels a reservation", tag: "Reservations", security: userAuth, headers: []*openapi.Parameter{ifMatch}, status: http.StatusOK, response: reservation, errors: []int{403, 404, 409, 412}},
+		{method: "GET", path: "/reservations/{id}/history", id: "getReservationHistory", summary: "Retrieves the audit trail of a reservation", tag: "Reservations", security: serviceAuth, status: http.StatusOK, response: &openapi.Schema{Type: "array", Items: auditEntry}},
+		{method: "POST", path: "/reservations/{id}/check-in", id: "selfCheckIn", summary: "Checks in the passenger from where they are", tag: "Check-In", security: userAuth, body: pointRequest, status: http.StatusOK, response: reservation, errors: []int{403, 404, 409, 422}},
+		{method: "GET", path: "/reservations/{id}/boarding-pass", id: "getBoardingPass", summary: "Retrieves the boarding pass of a reservation", tag: "Boarding Passes", security: userAuth, headers: []*openapi.Parameter{accept}, status: http.StatusOK, response: boardingPass, png: true, errors: []int{403, 404, 409}},
+
+		// Boarding passes
+		{method: "POST", path: "/boarding-passes:verify", id: "verifyBoardingPass", summary: "Verifies a boarding pass", tag: "Boarding Passes", security: userAuth, body: boardingPassRequest, status: http.StatusOK, response: verification, errors: []int{404}},
+		{method: "GET", path: "/boarding-passes/keys", id: "getBoardingPassKeys", summary: "Retrieves the public key used to sign boarding passes", tag: "Boarding Passes", status: http.StatusOK, response: keySet},
+
+		// Trips
+		{method: "POST", path: "/trips/{tripId}/completed", id: "completeTrip", summary: "Charges the reservations of a completed trip", tag: "Trips", security: serviceAuth, status: http.StatusOK, response: reservations},
+		{method: "POST", path: "/trips/{tripId}/cancelled", id: "cancelTrip", summary: "Cancels the reservations of a cancelled trip", tag: "Trips", security: serviceAuth, status: http.StatusOK, response: reservations},
+		{method: "GET", path: "/trips/{tripId}/reservations", id: "getTripReservations", summary: "Retrieves the roster of a trip", tag: "Trips", security: userAuth, status: http.StatusOK, response: reservations, errors: []int{403, 404}},
+		{method: "GET", path: "/trips/{tripId}/reservations:watch", id: "watchTripReservations", summary: "Streams the changes made to the roster of a trip as server-sent events", tag: "Trips", security: userAuth, status: http.StatusOK, response: reservation, events: true, errors: []int{403, 404}},
+		{method: "POST", path: "/trips/{tripId}/reservations/{id}/accept", id: "acceptReservation", summary: "Accepts a pending reservation", tag: "Trips", security: userAuth, headers: []*openapi.Parameter{ifMatch}, status: http.StatusOK, response: reservation, errors: []int{403, 404, 409, 412}},
+		{method: "POST", path: "/trips/{tripId}/reservations/{id}/reject", id: "rejectReservation", summary: "Rejects a pending reservation", tag: "Trips", security: userAuth, headers: []*openapi.Parameter{ifMatch}, body: decision, status: http.StatusOK, response: reservation, errors: []int{403, 404, 409, 412}},
+		{method: "POST", path: "/trips/{tripId}/reservations/{id}/check-in", id: "scanCheckIn", summary: "Checks in a passenger by scanning their check-in code", tag: "Check-In", security: userAuth, headers: []*openapi.Parameter{ifMatch}, body: scan, status: http.StatusOK, response: reservation, errors: []int{403, 404, 409, 412, 422}},
+		{method: "DELETE", path: "/trips/{tripId}/reservations/{id}", id: "cancelReservationByDriver", summary: "Cancels a passenger's reservation on the driver's trip", tag: "Trips", security: userAuth, headers: []*openapi.Parameter{ifMatch}, body: decision, status: http.StatusOK, response: reservation, errors: []int{403, 404, 409, 412}},
+
+		// Holds
+		{method: "POST", path: "/trips/{tripId}/holds", id: "createHold", summary: "Holds seats on a trip", tag: "Holds", security: userAuth, body: holdRequest, status: http.StatusCreated, response: hold, errors: []int{403, 404}},
+		{method: "GET", path: "/trips/{tripId}/holds/{id}", id: "getHold", summary: "Retrieves a hold", tag: "Holds", security: userAuth, status: http.StatusOK, response: hold, errors: []int{403, 404}},
+		{method: "DELETE", path: "/trips/{tripId}/holds/{id}", id: "releaseHold", summary: "Releases held seats", tag: "Holds", security: userAuth, status: http.StatusOK, response: hold, errors: []int{403, 404}},
+
+		// Webhooks
+		{method: "POST", path: "/webhooks", id: "createWebhook", summary: "Subscribes a webhook to reservation events", tag: "Webhooks", security: serviceAuth, body: webhookRequest, status: http.StatusCreated, response: webhook},
+		{method: "GET", path: "/webhooks", id: "getWebhooks", summary: "Retrieves every webhook", tag: "Webhooks", security: serviceAuth, status: http.StatusOK, response: &openapi.Schema{Type: "array", Items: webhook}},
+		{method: "GET", path: "/webhooks/{id}", id: "getWebhook", summary: "Retrieves a webhook", tag: "Webhooks", security: serviceAuth, status: http.StatusOK, response: webhook, errors: []int{404}},
+		{method: "DELETE", path: "/webhooks/{id}", id: "deleteWebhook", summary: "Unsubscribes a webhook", tag: "Webhooks", security: serviceAuth, status: http.StatusOK, response: webhook, errors: []int{404}},
+		{method: "GET", path: "/webhooks/{id}/deliveries", id: "getWebhookDeliveries", summary: "Retrieves the most recent deliveries of a webhook", tag: "Webhooks", security: serviceAuth, query: []*openapi.Parameter{limit}, status: http.StatusOK, response: &openapi.Schema{Type: "array", Items: delivery}, errors: []int{404}},
+
+		// GraphQL
+		{method: "POST", path: "/graphql", id: "executeGraphQL", summary: "Executes a GraphQL query on reservations and their trips", tag: "GraphQL", security: userAuth, body: graphQLRequest, status: http.StatusOK, response: graphQLResponse},
+
+		// Documentation
+		{method: "GET", path: "/openapi.json", id: "getOpenAPI", summary: "Retrieves this document", tag: "Documentation", status: http.StatusOK, response: &openapi.Schema{Type: "object"}},
+	}
+
+	for _, op := range operations {
+		doc.Add(op.method, op.path, op.document())
+	}
+
+	return doc
+}
+
+// document describes the operation as an OpenAPI operation.
+func (op *operation) document() *openapi.Operation {
+	o := &openapi.Operation{
+		OperationID: op.id,
+		Summary:     op.summary,
+		Tags:        []string{op.tag},
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	for _, m := range pathParameter.FindAllStringSubmatch(op.path, -1) {
+		o.Parameters = append(o.Parameters, &openapi.Parameter{Name: m[1], In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+	}
+	o.Parameters = append(o.Parameters, op.headers...)
+	o.Parameters = append(o.Parameters, op.query...)
+
+	for _, scheme := range op.security {
+		o.Security = append(o.Security, map[string][]string{scheme: {}})
+	}
+
+	if op.body != nil {
+		o.Parameters = append(o.Parameters, contentEncoding)
+		o.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: op.body}},
+		}
+		if op.imports {
+			o.RequestBody.Content = map[string]*openapi.MediaType{CSVContentType: {Schema: op.body}, JSONLinesContentType: {Schema: op.body}}
+		}
+	}
+
+	content := map[string]*openapi.MediaType{"application/json": {Schema: op.response}}
+	if op.events {
+		content = map[string]*openapi.MediaType{EventStreamContentType: {Schema: op.response}}
+	} else if op.exports {
+		content = map[string]*openapi.MediaType{CSVContentType: {Schema: op.response}, JSONLinesContentType: {Schema: op.response}}
+	} else if op.png {
+		content["image/png"] = &openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary"}}
+	}
+	o.Responses[strconv.Itoa(op.status)] = &openapi.Response{Description: http.StatusText(op.status), Headers: rateLimitHeaders, Content: content}
+
+	errors := op.errors
+	if op.body != nil {
+		errors = append(errors, http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType)
+	}
+	if op.response != nil {
+		errors = append(errors, http.StatusNotAcceptable)
+	}
+	if len(op.security) > 0 {
+		errors = append(errors, http.StatusUnauthorized)
+	}
+	errors = append(errors, http.StatusTooManyRequests, http.StatusInternalServerError)
+
+	errorContent := map[string]*openapi.MediaType{ProblemContentType: {Schema: &openapi.Schema{Ref: "#/components/schemas/Error"}}}
+	for _, code := range errors {
+		o.Responses[strconv.Itoa(code)] = &openapi.Response{Description: http.StatusText(code), Content: errorContent}
+	}
+	o.Responses[strconv.Itoa(http.StatusTooManyRequests)].Headers = map[string]*openapi.Header{
+		"Retry-After": {Description: "Seconds until the request can be made again", Schema: &openapi.Schema{Type: "integer"}},
+	}
+
+	return o
+}
+
+// segmentSchema returns the schema of the fields that describe which part of
+// a trip is reserved.
+func segmentSchema() *openapi.Schema {
+	s := openapi.SchemaOf(entity.Reservation{}).Require("tripId", "sourceId", "destinationId", "seats")
+	s.Property("seats").Between(entity.MinimumSeats, entity.MaximumSeats)
+
+	return s
+}
+
+// withMinLength requires the string properties with the given names to not be
+// empty, and returns the schema.
+func withMinLength(s *openapi.Schema, names ...string) *openapi.Schema {
+	one := 1
+	for _, n := range names {
+		s.Property(n).MinLength = &one
+	}
+
+	return s
+}
+
+// GetOpenAPI handles a request to retrieve the OpenAPI document describing
+// the service.
+func GetOpenAPI(doc *openapi.Document) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		err := json.NewEncoder(w).Encode(doc)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// Validate validates the body of a request against the request body schema of
+// its route in the OpenAPI document before handling it. A request whose body
+// does not match is rejected with a validation error listing every invalid
+// field.
+func Validate(doc *openapi.Document, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			return next(w, r)
+		}
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		body, err := readBody(r)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			return err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.Header.Del("Content-Encoding")
+
+		violations, err := doc.ValidateRequestBody(r.Method, path, body)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			return err
+		} else if len(violations) == 1 && violations[0].Field == "" {
+			w.Header().Set("Content-Type", "application/json")
+			return entity.NewValidationError(violations[0].String())
+		} else if len(violations) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			params := make([]*entity.InvalidParam, len(violations))
+			for i, v := range violations {
+				params[i] = &entity.InvalidParam{Name: v.Field, Reason: v.Reason}
+			}
+			return entity.NewInvalidParamsError(params)
+		}
+
+		return next(w, r)
+	}
+}
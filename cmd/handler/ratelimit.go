@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azure.com/ecovo/reservation-service/cmd/openapi"
+	"azure.com/ecovo/reservation-service/pkg/ratelimit"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+	"github.com/gorilla/mux"
+)
+
+// RateLimit limits how often a client can make requests on a route before
+// handling them, according to the limiter's rule for the route's operation in
+// the OpenAPI document. The state of the client's bucket is sent in the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers, and a request that exceeds the limit is rejected with a
+// Retry-After header.
+//
+// Clients are identified by the authenticated user, so RateLimit must be
+// wrapped by Auth on routes that require authentication. If the limiter
+// fails, because the store cannot be reached, the request is handled anyway; a
+// store that cannot take a token because of contention denies the request
+// instead.
+func RateLimit(doc *openapi.Document, limiter *ratelimit.Limiter, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			return next(w, r)
+		}
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		name := ratelimit.DefaultRoute
+		if op := doc.Operation(r.Method, path); op != nil {
+			name = op.OperationID
+		}
+
+		res, err := limiter.Allow(name, r)
+		if err != nil {
+			requestID, _ := requestid.FromContext(r.Context())
+			log.Printf("[Request ID=%s] error: failed to rate limit request, handling it anyway (%s)", requestID, err)
+			return next(w, r)
+		} else if res == nil {
+			return next(w, r)
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Rule.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", res.Rule.Limit, ceilSeconds(res.Rule.Period)))
+
+		if !res.Allowed {
+			h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			return TooManyRequestsError{fmt.Sprintf("too many requests, retry in %s second(s)", ceilSeconds(res.RetryAfter))}
+		}
+
+		return next(w, r)
+	}
+}
+
+// ceilSeconds formats a duration as a whole number of seconds, rounded up so
+// that clients do not retry too early.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
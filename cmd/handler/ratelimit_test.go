@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/ratelimit"
+	"github.com/gorilla/mux"
+)
+
+type failingStore struct{}
+
+func (s failingStore) Take(key string, rule *ratelimit.Rule) (*ratelimit.Result, error) {
+	return nil, errors.New("store is unavailable")
+}
+
+func newRateLimitedRouter(t *testing.T, store ratelimit.Store) *mux.Router {
+	limiter, err := ratelimit.NewLimiter(store, &ratelimit.Config{
+		Rules: map[string]*ratelimit.Rule{
+			ratelimit.DefaultRoute: {Limit: 100, Period: time.Minute},
+			"createReservation":    {Limit: 1, Period: time.Minute},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/reservations", RateLimit(OpenAPI(), limiter, ok)).Methods("POST")
+
+	return r
+}
+
+func TestRateLimit(t *testing.T) {
+	router := newRateLimitedRouter(t, ratelimit.NewMemoryStore())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/reservations", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("first request: got status %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	h := w.Header()
+	if h.Get("RateLimit-Limit") != "1" || h.Get("RateLimit-Remaining") != "0" || h.Get("RateLimit-Reset") != "60" || h.Get("RateLimit-Policy") != "1;w=60" {
+		t.Errorf("first request: got headers %v", h)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/reservations", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: got status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("second request: got Retry-After %q, want \"60\"", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimitHandlesRequestWhenStoreFails(t *testing.T) {
+	router := newRateLimitedRouter(t, failingStore{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/reservations", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	if w.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("got RateLimit-Limit %q, want none", w.Header().Get("RateLimit-Limit"))
+	}
+}
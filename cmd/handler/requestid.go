@@ -4,7 +4,7 @@ import (
 	"context"
 	"net/http"
 
-	"azure.com/ecovo/reservation-service/cmd/middleware/requestid"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
 	"github.com/google/uuid"
 )
 
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"azure.com/ecovo/reservation-service/pkg/audit"
 	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/pricing"
 	"azure.com/ecovo/reservation-service/pkg/reservation"
 	"github.com/gorilla/mux"
 )
@@ -15,21 +17,22 @@ func CreateReservation(service reservation.UseCase) Handler {
 		w.Header().Set("Content-Type", "application/json")
 
 		var res *entity.Reservation
-		err := json.NewDecoder(r.Body).Decode(&res)
+		err := decodeBody(r, &res)
 		if err != nil {
 			return err
 		}
 
-		res, err = service.Register(res)
+		res, err = service.Register(r.Context(), res)
 		if err != nil {
 			return err
 		}
 
+		setETag(w, res)
 		w.WriteHeader(http.StatusCreated)
 
 		err = json.NewEncoder(w).Encode(res)
 		if err != nil {
-			_ = service.Delete(entity.ID(res.ID))
+			_ = service.Unregister(r.Context(), res.ID)
 
 			return err
 		}
@@ -38,20 +41,157 @@ func CreateReservation(service reservation.UseCase) Handler {
 	}
 }
 
-// DeleteReservation handles a request to delete a reservation.
+// GetReservationByID handles a request to retrieve a reservation by its
+// unique identifier.
+func GetReservationByID(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+
+		res, err := service.FindForUser(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		setETag(w, res)
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetReservationHistory handles a request to retrieve the audit trail of a
+// reservation: every operation performed on it, by whom, and what changed.
+func GetReservationHistory(service audit.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+
+		entries, err := service.FindByReservationID(id)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(entries)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// QuoteReservation handles a request to compute the fare of a reservation
+// without booking it.
+func QuoteReservation(service pricing.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var res *entity.Reservation
+		err := decodeBody(r, &res)
+		if err != nil {
+			return err
+		}
+
+		fare, err := service.Quote(res)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(fare)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteReservation handles a request to cancel a reservation. The cancelled
+// reservation, along with the cancellation rule that was applied and its fee,
+// is returned in the response.
+//
+// When the request has an If-Match header, the reservation is only cancelled
+// if its entity tag matches.
 func DeleteReservation(service reservation.UseCase) Handler {
 	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
 		vars := mux.Vars(r)
 
 		id := entity.NewIDFromHex(vars["id"])
 
-		err := service.Delete(id)
+		res, err := service.FindByID(r.Context(), id)
 		if err != nil {
 			return err
 		}
 
+		version, err := checkIfMatch(r, res)
+		if err != nil {
+			return err
+		}
+
+		res, err = service.Delete(r.Context(), id, version)
+		if err != nil {
+			return err
+		}
+
+		setETag(w, res)
 		w.WriteHeader(http.StatusOK)
 
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// CheckInReservation handles a request, sent by a passenger, to check in by
+// themselves from where they are.
+func CheckInReservation(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+
+		var point *entity.Point
+		err := decodeBody(r, &point)
+		if err != nil {
+			return err
+		}
+
+		res, err := service.SelfCheckIn(r.Context(), id, point, 0)
+		if err != nil {
+			return err
+		}
+
+		setETag(w, res)
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}
 }
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+)
+
+const (
+	// CSVContentType represents the media type of reservations exported or
+	// imported as comma-separated values.
+	CSVContentType = "text/csv"
+
+	// JSONLinesContentType represents the media type of reservations exported
+	// or imported as JSON Lines.
+	JSONLinesContentType = "application/x-ndjson"
+)
+
+// MaxImportSize represents the maximum size of the body of an import, in
+// bytes, once it is decompressed.
+const MaxImportSize = 64 << 20
+
+// transferFormats are the formats of exports and imports, by media type.
+var transferFormats = map[string]reservation.Format{
+	CSVContentType:       reservation.FormatCSV,
+	JSONLinesContentType: reservation.FormatJSONLines,
+}
+
+// An importResult is the outcome of an import. Each reservation that was not
+// imported is reported with its line, and the error it would have caused if
+// it was registered on its own.
+type importResult struct {
+	DryRun   bool               `json:"dryRun"`
+	Lines    int                `json:"lines"`
+	Imported int                `json:"imported"`
+	Failed   int                `json:"failed"`
+	Errors   []*importLineError `json:"errors"`
+}
+
+type importLineError struct {
+	Line  int       `json:"line"`
+	ID    entity.ID `json:"id,omitempty"`
+	Error *Error    `json:"error"`
+}
+
+// ExportReservations handles a request to stream the reservations created
+// in a date range, made on a trip, by a user or with a status, as CSV or JSON
+// Lines depending on the request's Accept header.
+func ExportReservations(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		mediaType, err := negotiate(r, CSVContentType, JSONLinesContentType)
+		if err != nil {
+			return err
+		}
+
+		c, err := exportCriteria(r.URL.Query())
+		if err != nil {
+			return err
+		}
+
+		format := transferFormats[mediaType]
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"reservations.%s\"", format))
+
+		tw := &trackingWriter{w: w}
+		n, err := service.Export(r.Context(), c, format, tw)
+		if err != nil && !tw.wrote {
+			w.Header().Del("Content-Disposition")
+			return err
+		} else if err != nil {
+			// The status code was already sent, so the export is aborted to
+			// let the client know it is incomplete.
+			requestID, _ := requestid.FromContext(r.Context())
+			log.Printf("[Request ID=%s] error: export aborted after %d reservation(s) (%s)", requestID, n, err)
+			panic(http.ErrAbortHandler)
+		}
+
+		return nil
+	}
+}
+
+// exportCriteria parses the criteria of an export from the query parameters
+// of its request.
+func exportCriteria(query url.Values) (*reservation.Criteria, error) {
+	c := &reservation.Criteria{
+		TripID: entity.NewIDFromHex(query.Get("tripId")),
+		UserID: entity.NewIDFromHex(query.Get("userId")),
+		Status: entity.Status(query.Get("status")),
+	}
+
+	params := []*entity.InvalidParam{}
+	if from := query.Get("from"); from != "" {
+		t, err := reservation.ParseTime(from)
+		if err != nil {
+			params = append(params, &entity.InvalidParam{Name: "from", Reason: "must be an RFC 3339 date or date and time"})
+		}
+		c.CreatedFrom = t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := reservation.ParseTime(to)
+		if err != nil {
+			params = append(params, &entity.InvalidParam{Name: "to", Reason: "must be an RFC 3339 date or date and time"})
+		}
+		c.CreatedTo = t
+	}
+	if len(params) > 0 {
+		return nil, entity.NewInvalidParamsError(params)
+	}
+
+	return c, nil
+}
+
+// A trackingWriter is a writer that tracks whether or not anything was
+// written to the response.
+type trackingWriter struct {
+	w     http.ResponseWriter
+	wrote bool
+}
+
+func (t *trackingWriter) Write(b []byte) (int, error) {
+	t.wrote = true
+	return t.w.Write(b)
+}
+
+// ImportReservations handles a request to import reservations from a body in
+// CSV or JSON Lines, depending on its Content-Type header. Every reservation
+// that is not imported is reported in the response with its line. With the
+// dryRun query parameter set to true, the reservations are only validated.
+func ImportReservations(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		contentType := r.Header.Get("Content-Type")
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		format, ok := transferFormats[mediaType]
+		if err != nil || !ok {
+			return UnsupportedMediaTypeError{fmt.Sprintf("Content-Type \"%s\" is not supported, use %s or %s", contentType, CSVContentType, JSONLinesContentType)}
+		}
+
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+		body, err := decompress(r)
+		if err != nil {
+			return err
+		}
+
+		report, err := service.Import(r.Context(), format, &limitedReader{body, MaxImportSize}, dryRun)
+		if err != nil {
+			return err
+		}
+
+		result := &importResult{
+			DryRun:   report.DryRun,
+			Lines:    report.Lines,
+			Imported: report.Imported,
+			Failed:   report.Failed,
+			Errors:   make([]*importLineError, len(report.Errors)),
+		}
+		requestID, _ := requestid.FromContext(r.Context())
+		for i, lineErr := range report.Errors {
+			handlerErr := WrapError(lineErr.Err)
+			log.Printf("[Request ID=%s] error: import line %d: %s", requestID, lineErr.Line, handlerErr)
+
+			result.Errors[i] = &importLineError{lineErr.Line, lineErr.ID, handlerErr}
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// A limitedReader is a reader that fails with a BodyTooLargeError once more
+// than n bytes were read from it.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(b []byte) (int, error) {
+	if l.n < 0 {
+		return 0, BodyTooLargeError{fmt.Sprintf("request body must not be larger than %d bytes", MaxImportSize)}
+	}
+
+	if int64(len(b)) > l.n+1 {
+		b = b[:l.n+1]
+	}
+	n, err := l.r.Read(b)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return 0, BodyTooLargeError{fmt.Sprintf("request body must not be larger than %d bytes", MaxImportSize)}
+	}
+
+	return n, err
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	"github.com/gorilla/mux"
+)
+
+// CompleteTrip handles a request, sent by the trip-service, notifying that a
+// trip was completed so that the reservations made on it are charged.
+func CompleteTrip(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+
+		reservations, err := service.CompleteTrip(r.Context(), tripID)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(reservations)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// CancelTrip handles a request, sent by the trip-service, notifying that a
+// trip was cancelled by its driver so that the reservations made on it are
+// cancelled.
+func CancelTrip(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+
+		reservations, err := service.CancelTrip(r.Context(), tripID)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(reservations)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetTripReservations handles a request, sent by a trip's driver, to retrieve
+// the reservations made on the trip.
+func GetTripReservations(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+
+		reservations, err := service.FindByTripID(r.Context(), tripID)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(reservations)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// AcceptTripReservation handles a request, sent by a trip's driver, to accept
+// a pending reservation made on the trip.
+func AcceptTripReservation(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+		id := entity.NewIDFromHex(vars["id"])
+
+		version, err := matchedVersion(r, service, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := service.Accept(r.Context(), tripID, id, version)
+		if err != nil {
+			return err
+		}
+
+		setETag(w, res)
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// A driverDecision is the body of a request, sent by a trip's driver, to
+// reject or cancel a reservation made on the trip.
+type driverDecision struct {
+	Reason string `json:"reason"`
+}
+
+// RejectTripReservation handles a request, sent by a trip's driver, to reject
+// a pending reservation made on the trip.
+func RejectTripReservation(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+		id := entity.NewIDFromHex(vars["id"])
+
+		var d driverDecision
+		err := decodeBody(r, &d)
+		if err != nil {
+			return err
+		}
+
+		version, err := matchedVersion(r, service, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := service.Reject(r.Context(), tripID, id, d.Reason, version)
+		if err != nil {
+			return err
+		}
+
+		setETag(w, res)
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteTripReservation handles a request, sent by a trip's driver, to cancel
+// a passenger's reservation made on the trip.
+func DeleteTripReservation(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+		id := entity.NewIDFromHex(vars["id"])
+
+		var d driverDecision
+		err := decodeBody(r, &d)
+		if err != nil {
+			return err
+		}
+
+		version, err := matchedVersion(r, service, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := service.CancelByDriver(r.Context(), tripID, id, d.Reason, version)
+		if err != nil {
+			return err
+		}
+
+		setETag(w, res)
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// A checkInScan is the body of a request, sent by a trip's driver, to check in
+// a passenger by scanning their check-in code.
+type checkInScan struct {
+	Code string `json:"code"`
+}
+
+// CheckInTripReservation handles a request, sent by a trip's driver, to check
+// in the passenger of a reservation made on the trip.
+func CheckInTripReservation(service reservation.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		tripID := entity.NewIDFromHex(vars["tripId"])
+		id := entity.NewIDFromHex(vars["id"])
+
+		var scan checkInScan
+		err := decodeBody(r, &scan)
+		if err != nil {
+			return err
+		}
+
+		version, err := matchedVersion(r, service, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := service.CheckIn(r.Context(), tripID, id, scan.Code, version)
+		if err != nil {
+			return err
+		}
+
+		setETag(w, res)
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(res)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/webhook"
+	"github.com/gorilla/mux"
+)
+
+// CreateWebhook handles a request to subscribe a webhook to reservation
+// events.
+func CreateWebhook(service webhook.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		var hook *entity.Webhook
+		err := decodeBody(r, &hook)
+		if err != nil {
+			return err
+		}
+
+		hook, err = service.Register(hook)
+		if err != nil {
+			return err
+		}
+
+		hook.Secret = ""
+
+		w.WriteHeader(http.StatusCreated)
+
+		err = json.NewEncoder(w).Encode(hook)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetWebhooks handles a request to retrieve every webhook.
+func GetWebhooks(service webhook.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		hooks, err := service.FindAll()
+		if err != nil {
+			return err
+		}
+
+		for _, hook := range hooks {
+			hook.Secret = ""
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(hooks)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GetWebhookByID handles a request to retrieve a webhook by its unique
+// identifier.
+func GetWebhookByID(service webhook.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+
+		hook, err := service.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		hook.Secret = ""
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(hook)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// DeleteWebhook handles a request to unsubscribe a webhook.
+func DeleteWebhook(service webhook.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+
+		err := service.Delete(id)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		return nil
+	}
+}
+
+// GetWebhookDeliveries handles a request to retrieve the most recent
+// deliveries made to a webhook, along with their attempts.
+//
+// The number of deliveries can be specified with the limit query parameter.
+func GetWebhookDeliveries(service webhook.UseCase) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+
+		id := entity.NewIDFromHex(vars["id"])
+
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			limit = webhook.DefaultDeliveriesLimit
+		}
+
+		deliveries, err := service.FindDeliveries(id, limit)
+		if err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(deliveries)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -1,26 +1,55 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"time"
 
+	"azure.com/ecovo/reservation-service/cmd/env"
+	"azure.com/ecovo/reservation-service/cmd/graph"
 	"azure.com/ecovo/reservation-service/cmd/handler"
 	"azure.com/ecovo/reservation-service/cmd/middleware/auth"
+	"azure.com/ecovo/reservation-service/cmd/rpc"
+	"azure.com/ecovo/reservation-service/cmd/setup"
+	"azure.com/ecovo/reservation-service/pkg/boardingpass"
 	"azure.com/ecovo/reservation-service/pkg/db"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"azure.com/ecovo/reservation-service/pkg/ratelimit"
+	"azure.com/ecovo/reservation-service/pkg/reconciliation"
 	"azure.com/ecovo/reservation-service/pkg/reservation"
-	"azure.com/ecovo/reservation-service/pkg/trip"
+	"azure.com/ecovo/reservation-service/pkg/reservationpb"
+	"azure.com/ecovo/reservation-service/pkg/webhook"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 )
 
+// holdSweepInterval represents how often expired holds are released.
+const holdSweepInterval = 30 * time.Second
+
+// noShowInterval represents how often passengers who did not check in are
+// marked as no-shows.
+const noShowInterval = 5 * time.Minute
+
 func main() {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+
 	authConfig := auth.Config{
 		Domain:               os.Getenv("AUTH_DOMAIN"),
 		BasicAuthCredentials: os.Getenv("AUTH_CREDENTIALS"),
@@ -37,43 +66,249 @@ func main() {
 		"basic":  authBasicValidator,
 		"bearer": authTokenValidator,
 	}
+	serviceAuthValidators := map[string]auth.Validator{
+		"basic": authBasicValidator,
+	}
 
-	dbConnectionTimeout, err := time.ParseDuration(os.Getenv("DB_CONNECTION_TIMEOUT") + "s")
+	db, err := db.New(env.DB())
 	if err != nil {
-		dbConnectionTimeout = db.DefaultConnectionTimeout
-	}
-	dbConfig := db.Config{
-		Host:              os.Getenv("DB_HOST"),
-		Username:          os.Getenv("DB_USERNAME"),
-		Password:          os.Getenv("DB_PASSWORD"),
-		Name:              os.Getenv("DB_NAME"),
-		ConnectionTimeout: dbConnectionTimeout}
-	db, err := db.New(&dbConfig)
+		log.Fatal(err)
+	}
+
+	// Running the service with the "migrate" argument only applies the
+	// migrations. Otherwise, they are applied on boot unless they are skipped.
+	migrateOnly := len(os.Args) > 1 && os.Args[1] == "migrate"
+	if migrateOnly || !env.SkipMigrations() {
+		versions, err := db.Migrate(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("db: applied %d migration(s) %v", len(versions), versions)
+	}
+	if migrateOnly {
+		return
+	}
+
+	services, err := setup.New(db)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer services.Close()
+
+	// Running the service with the "reconcile" argument only reconciles the
+	// reservations with the trip-service and prints the report.
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		reconcile(services.Reconciliation, os.Args[2:])
+		return
+	}
 
-	tripRepository, err := trip.NewRestRepository(os.Getenv("TRIP_SERVICE_DOMAIN"), os.Getenv("AUTH_CREDENTIALS"))
+	go reconciliation.Schedule(context.Background(), services.Reconciliation, env.ReconciliationInterval())
+	go hold.Sweep(context.Background(), services.Holds, holdSweepInterval)
+	go reservation.WatchNoShows(context.Background(), services.Reservations, noShowInterval)
+
+	webhookDispatcherConfig := webhook.DispatcherConfig{
+		Interval:    webhook.DefaultInterval,
+		Timeout:     webhook.DefaultTimeout,
+		MaxAttempts: webhook.DefaultMaxAttempts,
+		Backoff:     webhook.DefaultBackoff,
+		BatchSize:   webhook.DefaultBatchSize,
+	}
+	webhookDispatcher, err := webhook.NewDispatcher(services.WebhookRepository, &webhookDispatcherConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
+	go webhookDispatcher.Run(context.Background())
+
+	var boardingPassSigner *boardingpass.Signer
+	if key := os.Getenv("BOARDING_PASS_KEY"); key != "" {
+		seed, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		boardingPassSigner, err = boardingpass.NewSigner(seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		// Without a configured key, every instance signs boarding passes with
+		// its own key, so a boarding pass can only be verified by the instance
+		// that issued it, and no longer once it restarts.
+		log.Printf("boardingpass: WARNING: BOARDING_PASS_KEY is missing, generating a temporary key. Boarding passes issued by this instance cannot be verified by other instances, nor by this one once it restarts, and /boarding-passes/keys only returns this instance's key. Set BOARDING_PASS_KEY when running more than one instance.")
+		boardingPassSigner, err = boardingpass.GenerateSigner()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	boardingPassUseCase := boardingpass.NewService(services.Reservations, boardingPassSigner)
 
-	tripUseCase := trip.NewService(tripRepository)
+	graphQLSchema, err := graph.NewSchema(services.Reservations, services.Trips)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	reservationRepository, err := reservation.NewMongoRepository(db.Reservations)
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if env.RateLimitStore() == "mongo" {
+		rateLimitStore, err = ratelimit.NewMongoStore(db.RateLimits)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	rateLimiter, err := ratelimit.NewLimiter(rateLimitStore, env.RateLimits())
 	if err != nil {
 		log.Fatal(err)
 	}
-	reservationUseCase := reservation.NewService(reservationRepository, tripUseCase)
+
+	openAPI := handler.OpenAPI()
 
 	r := mux.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return handler.Negotiate(openAPI, next)
+	})
+
+	// Documentation
+	r.Handle("/openapi.json", handler.RequestID(handler.RateLimit(openAPI, rateLimiter, handler.GetOpenAPI(openAPI)))).
+		Methods("GET")
 
 	// Reservations
-	r.Handle("/reservations", handler.RequestID(handler.Auth(authValidators, handler.CreateReservation(reservationUseCase)))).
-		Methods("POST").
-		HeadersRegexp("Content-Type", "application/(json|json; charset=utf8)")
-	r.Handle("/reservations/{id}", handler.RequestID(handler.Auth(authValidators, handler.DeleteReservation(reservationUseCase)))).
-		Methods("DELETE").
-		HeadersRegexp("Content-Type", "application/json")
+	r.Handle("/reservations", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.CreateReservation(services.Reservations)))))).
+		Methods("POST")
+	r.Handle("/reservations:batch", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.CreateReservationBatch(services.Reservations)))))).
+		Methods("POST")
+	r.Handle("/reservations:batchCancel", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.DeleteReservationBatch(services.Reservations)))))).
+		Methods("POST")
+	r.Handle("/reservations/quote", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.QuoteReservation(services.Pricing)))))).
+		Methods("POST")
+	r.Handle("/reservations:export", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.ExportReservations(services.Reservations))))).
+		Methods("GET")
+	r.Handle("/reservations:import", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.ImportReservations(services.Reservations))))).
+		Methods("POST")
+	r.Handle("/reservations:watch", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.WatchReservations(services.Reservations))))).
+		Methods("GET")
+	r.Handle("/reservations/{id}", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetReservationByID(services.Reservations))))).
+		Methods("GET")
+	r.Handle("/reservations/{id}", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.DeleteReservation(services.Reservations))))).
+		Methods("DELETE")
+	r.Handle("/reservations/{id}/history", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetReservationHistory(services.Audit))))).
+		Methods("GET")
+	r.Handle("/reservations/{id}/check-in", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.CheckInReservation(services.Reservations)))))).
+		Methods("POST")
+	r.Handle("/reservations/{id}/boarding-pass", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetBoardingPass(boardingPassUseCase))))).
+		Methods("GET")
+
+	// Boarding passes
+	r.Handle("/boarding-passes:verify", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.VerifyBoardingPass(boardingPassUseCase)))))).
+		Methods("POST")
+	r.Handle("/boarding-passes/keys", handler.RequestID(handler.RateLimit(openAPI, rateLimiter, handler.GetBoardingPassKeys(boardingPassUseCase)))).
+		Methods("GET")
+
+	// Trips
+	r.Handle("/trips/{tripId}/completed", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.CompleteTrip(services.Reservations))))).
+		Methods("POST")
+	r.Handle("/trips/{tripId}/cancelled", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.CancelTrip(services.Reservations))))).
+		Methods("POST")
+
+	r.Handle("/trips/{tripId}/reservations", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetTripReservations(services.Reservations))))).
+		Methods("GET")
+	r.Handle("/trips/{tripId}/reservations:watch", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.WatchTripReservations(services.Reservations))))).
+		Methods("GET")
+	r.Handle("/trips/{tripId}/reservations/{id}/accept", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.AcceptTripReservation(services.Reservations))))).
+		Methods("POST")
+	r.Handle("/trips/{tripId}/reservations/{id}/reject", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.RejectTripReservation(services.Reservations)))))).
+		Methods("POST")
+	r.Handle("/trips/{tripId}/reservations/{id}/check-in", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.CheckInTripReservation(services.Reservations)))))).
+		Methods("POST")
+	r.Handle("/trips/{tripId}/reservations/{id}", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.DeleteTripReservation(services.Reservations)))))).
+		Methods("DELETE")
+
+	r.Handle("/trips/{tripId}/holds", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.CreateHold(services.Holds)))))).
+		Methods("POST")
+	r.Handle("/trips/{tripId}/holds/{id}", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetHoldByID(services.Holds))))).
+		Methods("GET")
+	r.Handle("/trips/{tripId}/holds/{id}", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.DeleteHold(services.Holds))))).
+		Methods("DELETE")
+
+	// Webhooks
+	r.Handle("/webhooks", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.CreateWebhook(services.Webhooks)))))).
+		Methods("POST")
+	r.Handle("/webhooks", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetWebhooks(services.Webhooks))))).
+		Methods("GET")
+	r.Handle("/webhooks/{id}", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetWebhookByID(services.Webhooks))))).
+		Methods("GET")
+	r.Handle("/webhooks/{id}", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.DeleteWebhook(services.Webhooks))))).
+		Methods("DELETE")
+	r.Handle("/webhooks/{id}/deliveries", handler.RequestID(handler.Auth(serviceAuthValidators, handler.RateLimit(openAPI, rateLimiter, handler.GetWebhookDeliveries(services.Webhooks))))).
+		Methods("GET")
+
+	// GraphQL
+	r.Handle("/graphql", handler.RequestID(handler.Auth(authValidators, handler.RateLimit(openAPI, rateLimiter, handler.Validate(openAPI, handler.ExecuteGraphQL(graphQLSchema)))))).
+		Methods("POST")
+
+	// Every route must be described in the OpenAPI document, so that it does
+	// not drift from the handlers.
+	err = r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			if openAPI.Operation(method, path) == nil {
+				return fmt.Errorf("openapi: route %s %s is not documented", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(rpc.UnaryInterceptors(authValidators)...),
+		grpc.ChainStreamInterceptor(rpc.StreamInterceptors(authValidators)...),
+	)
+	reservationpb.RegisterReservationServiceServer(grpcServer, rpc.NewServer(services.Reservations))
+	go func() {
+		log.Fatal(grpcServer.Serve(grpcListener))
+	}()
+
 	log.Fatal(http.ListenAndServe(":"+port, handlers.LoggingHandler(os.Stdout, r)))
 }
+
+// reconcile reconciles the reservations made on the trips with the given IDs,
+// or on every trip if none are given, and prints the report as JSON. The
+// discrepancies are only repaired when the -repair flag is set.
+func reconcile(service reconciliation.UseCase, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	repair := flags.Bool("repair", false, "repair the discrepancies instead of only reporting them")
+	flags.Parse(args)
+
+	var report *reconciliation.Report
+	var err error
+	if flags.NArg() > 0 {
+		tripIDs := []entity.ID{}
+		for _, arg := range flags.Args() {
+			tripIDs = append(tripIDs, entity.NewIDFromHex(arg))
+		}
+
+		report, err = service.Reconcile(tripIDs, *repair)
+	} else {
+		report, err = service.ReconcileAll(*repair)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	err = enc.Encode(report)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
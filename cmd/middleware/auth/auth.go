@@ -1,22 +1,14 @@
 package auth
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
-)
 
-// UserInfo contains a user's basic information extracted from an access token.
-type UserInfo struct {
-	SubID     string `json:"sub,omitempty"`
-	FirstName string `json:"given_name"`
-	LastName  string `json:"family_name"`
-	Picture   string `json:"picture"`
-	Email     string `json:"email"`
-}
+	"azure.com/ecovo/reservation-service/pkg/identity"
+)
 
 // Config contains the information required to configure a validator to make
 // requests to validate a request's authorization header.
@@ -43,7 +35,7 @@ func (conf *Config) validate() error {
 type Validator interface {
 	// Validate validates an authorization and returns the authenticated user's
 	// information.
-	Validate(credentials string) (*UserInfo, error)
+	Validate(credentials string) (*identity.UserInfo, error)
 }
 
 // A TokenValidator is a validator that validates a bearer token in an
@@ -71,7 +63,7 @@ func NewTokenValidator(conf *Config) (Validator, error) {
 // in the token validator's configuration to validate the bearer token present
 // in the authorization header and returns the authenticated user's
 // information.
-func (validator *TokenValidator) Validate(credentials string) (*UserInfo, error) {
+func (validator *TokenValidator) Validate(credentials string) (*identity.UserInfo, error) {
 	req, err := http.NewRequest("GET", "https://"+validator.conf.Domain+"/userinfo", nil)
 	if err != nil {
 		return nil, UnauthorizedError{fmt.Sprintf("auth.TokenValidator: failed to create request (%s)", err)}
@@ -88,7 +80,7 @@ func (validator *TokenValidator) Validate(credentials string) (*UserInfo, error)
 		return nil, UnauthorizedError{fmt.Sprintf("auth: failed to validate token")}
 	}
 
-	var userInfo UserInfo
+	var userInfo identity.UserInfo
 	err = json.NewDecoder(resp.Body).Decode(&userInfo)
 	if err != nil {
 		return nil, UnauthorizedError{fmt.Sprintf("auth: failed to decode user info (%s)", err)}
@@ -120,37 +112,38 @@ func NewBasicAuthValidator(conf *Config) (Validator, error) {
 // Validate compares the authorization header with the base64 encoded username
 // and password stored in its configuration. It does not return the
 // authenticated user's information, since there is no user.
-func (validator *BasicAuthValidator) Validate(credentials string) (*UserInfo, error) {
+func (validator *BasicAuthValidator) Validate(credentials string) (*identity.UserInfo, error) {
 	if strings.Compare(credentials, validator.conf.BasicAuthCredentials) == 0 {
-		return &UserInfo{}, nil
+		return &identity.UserInfo{}, nil
 	}
 
 	return nil, UnauthorizedError{"auth: failed to decode user info"}
 }
 
-type contextKey string
-
-func (c contextKey) String() string {
-	return "auth." + string(c)
-}
+// Authenticate validates an authorization header (ex. "Bearer {token}") using
+// the validator for its type, and returns the authenticated user's
+// information.
+func Authenticate(validators map[string]Validator, header string) (*identity.UserInfo, error) {
+	authType, authCredentials, err := parseHeader(header)
+	if err != nil {
+		return nil, UnauthorizedError{Msg: fmt.Sprintf("auth: %s", err)}
+	}
 
-const (
-	// UserInfoContextKey represents the key used to store and retrieve the
-	// user information from the request context.
-	UserInfoContextKey = contextKey("userInfo")
-)
+	authType = strings.ToLower(authType)
 
-// FromContext extracts an authenticated user's information from the request's
-// context.
-func FromContext(ctx context.Context) (*UserInfo, error) {
-	if ctx == nil {
-		return nil, fmt.Errorf("auth: request context is nil")
+	v, ok := validators[authType]
+	if !ok {
+		return nil, UnauthorizedError{Msg: fmt.Sprintf("auth: no validator found for %s", authType)}
 	}
 
-	userInfo, ok := ctx.Value(UserInfoContextKey).(*UserInfo)
-	if !ok {
-		return nil, fmt.Errorf("auth: %s not found in context", UserInfoContextKey)
+	return v.Validate(authCredentials)
+}
+
+func parseHeader(header string) (string, string, error) {
+	headerParts := strings.Split(header, " ")
+	if len(headerParts) < 2 {
+		return "", "", fmt.Errorf("failed to parse authorization header")
 	}
 
-	return userInfo, nil
+	return headerParts[0], headerParts[1], nil
 }
@@ -0,0 +1,138 @@
+package openapi
+
+import "strings"
+
+// Version represents the version of the OpenAPI specification documents
+// follow.
+const Version = "3.0.3"
+
+// A Document is an OpenAPI document describing an API.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       *Info               `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components"`
+}
+
+// Info contains the metadata of an API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// A PathItem contains the operations available on a path, by lowercase HTTP
+// method.
+type PathItem map[string]*Operation
+
+// An Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// A Parameter describes a single operation parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// A RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// A Response describes a single response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// A Header describes a header of a response.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// A MediaType contains the schema of a body of a given media type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components contains the schemas and security schemes that are referenced
+// throughout a document.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// A SecurityScheme describes a way of authenticating requests.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// New creates an empty document for the API with the given title and version.
+func New(title string, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    &Info{Title: title, Version: version},
+		Paths:   make(map[string]PathItem),
+		Components: &Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+	}
+}
+
+// Define adds the schema to the document's components under the given name,
+// and returns a schema that references it.
+func (d *Document) Define(name string, s *Schema) *Schema {
+	d.Components.Schemas[name] = s
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Add adds the operation to the document for the given method and path. The
+// path uses the same templates as the router (ex. /reservations/{id}).
+func (d *Document) Add(method string, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = PathItem{}
+		d.Paths[path] = item
+	}
+
+	item[strings.ToLower(method)] = op
+}
+
+// Operation retrieves the operation for the given method and path, if it is
+// documented.
+func (d *Document) Operation(method string, path string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+
+	return item[strings.ToLower(method)]
+}
+
+// Resolve returns the schema a schema references, or the schema itself if it
+// is not a reference.
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+
+	return s
+}
@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// A Schema describes the structure of a JSON value, following the subset of
+// JSON Schema supported by OpenAPI.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf generates the schema of the JSON encoding of the given value, based
+// on its type and the json tags of its fields. Fields are not required; use
+// Require to mark the ones a request must contain.
+func SchemaOf(v interface{}) *Schema {
+	return schemaOfType(reflect.TypeOf(v))
+}
+
+func schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addProperties(s, t)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// addProperties adds a property to the schema for every field of the struct
+// type that is encoded in JSON. The fields of embedded structs are added as if
+// they were fields of the struct itself.
+func addProperties(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name := f.Name
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if n := strings.Split(tag, ",")[0]; n != "" {
+			name = n
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			addProperties(s, ft)
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		s.Properties[name] = schemaOfType(f.Type)
+	}
+}
+
+// Require marks the properties with the given names as required, and returns
+// the schema.
+func (s *Schema) Require(names ...string) *Schema {
+	s.Required = append(s.Required, names...)
+
+	return s
+}
+
+// Property returns the schema of the property with the given name.
+func (s *Schema) Property(name string) *Schema {
+	return s.Properties[name]
+}
+
+// Only removes every property but the ones with the given names, and returns
+// the schema.
+func (s *Schema) Only(names ...string) *Schema {
+	properties := make(map[string]*Schema)
+	for _, n := range names {
+		if p, ok := s.Properties[n]; ok {
+			properties[n] = p
+		}
+	}
+	s.Properties = properties
+
+	required := []string{}
+	for _, n := range s.Required {
+		if _, ok := properties[n]; ok {
+			required = append(required, n)
+		}
+	}
+	s.Required = required
+
+	return s
+}
+
+// Between sets the minimum and maximum of a numeric schema, and returns it.
+func (s *Schema) Between(min float64, max float64) *Schema {
+	s.Minimum = &min
+	s.Maximum = &max
+
+	return s
+}
+
+// OneOf sets the values a schema allows, and returns it.
+func (s *Schema) OneOf(values ...string) *Schema {
+	s.Enum = []interface{}{}
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+
+	return s
+}
@@ -0,0 +1,176 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// A Violation describes why a value does not match a schema.
+type Violation struct {
+	// Field is the path to the invalid value (ex. reservations[0].seats), or
+	// empty if the value itself is invalid.
+	Field  string
+	Reason string
+}
+
+func (v *Violation) String() string {
+	if v.Field == "" {
+		return "request body " + v.Reason
+	}
+
+	return v.Field + " " + v.Reason
+}
+
+// ValidateRequestBody validates the body of a request for the operation with
+// the given method and path against the operation's request body schema, and
+// returns every violation that was found. Operations that are not documented,
+// or that do not take a body, accept any body. An error is returned if the
+// body is not valid JSON.
+func (d *Document) ValidateRequestBody(method string, path string, body []byte) ([]*Violation, error) {
+	op := d.Operation(method, path)
+	if op == nil || op.RequestBody == nil {
+		return nil, nil
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return violation("", "is missing"), nil
+		}
+		return nil, nil
+	}
+
+	mt, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Validate(mt.Schema, v), nil
+}
+
+// Validate validates a decoded JSON value against the schema, and returns every
+// violation that was found. Numbers are expected to be
+// decoded as json.Number. A null value is treated as if it were missing.
+func (d *Document) Validate(s *Schema, v interface{}) []*Violation {
+	return d.validate(s, v, "")
+}
+
+func (d *Document) validate(s *Schema, v interface{}, path string) []*Violation {
+	s = d.Resolve(s)
+	if s == nil || v == nil {
+		return nil
+	}
+
+	switch s.Type {
+	case "object":
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return violation(path, "must be an object")
+		}
+		return d.validateObject(s, o, path)
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			return violation(path, "must be an array")
+		}
+		violations := []*Violation{}
+		for i, item := range a {
+			violations = append(violations, d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return violations
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return violation(path, "must be a string")
+		}
+		if s.MinLength != nil && *s.MinLength == 1 && str == "" {
+			return violation(path, "must not be empty")
+		} else if s.MinLength != nil && len(str) < *s.MinLength {
+			return violation(path, fmt.Sprintf("must be at least %d characters long", *s.MinLength))
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return violation(path, fmt.Sprintf("must be one of %v", s.Enum))
+		}
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok && s.Type == "integer" {
+			return violation(path, "must be an integer")
+		} else if !ok {
+			return violation(path, "must be a number")
+		}
+		if _, err := n.Int64(); s.Type == "integer" && err != nil {
+			return violation(path, "must be an integer")
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return violation(path, "must be a number")
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return violation(path, fmt.Sprintf("must be at least %v", *s.Minimum))
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return violation(path, fmt.Sprintf("must be at most %v", *s.Maximum))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return violation(path, "must be a boolean")
+		}
+	}
+
+	return nil
+}
+
+func (d *Document) validateObject(s *Schema, o map[string]interface{}, path string) []*Violation {
+	violations := []*Violation{}
+	for _, name := range s.Required {
+		if o[name] == nil {
+			violations = append(violations, violation(join(path, name), "is required")...)
+		}
+	}
+
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if p, ok := s.Properties[name]; ok {
+			violations = append(violations, d.validate(p, o[name], join(path, name))...)
+		}
+	}
+
+	return violations
+}
+
+func violation(path string, reason string) []*Violation {
+	return []*Violation{{path, reason}}
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func contains(values []interface{}, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
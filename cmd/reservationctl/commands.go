@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reconciliation"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	"azure.com/ecovo/reservation-service/pkg/webhook"
+)
+
+// get prints the reservations with the given IDs.
+func get(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("get", flag.ExitOnError)
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("get: missing reservation ID")
+	}
+
+	reservations := []*entity.Reservation{}
+	for _, arg := range flags.Args() {
+		res, err := a.Reservations.FindByID(ctx, entity.NewIDFromHex(arg))
+		if err != nil {
+			return err
+		}
+
+		reservations = append(reservations, res)
+	}
+
+	return a.out.reservations(reservations)
+}
+
+// list prints the reservations made on a trip, by a user or with a status, or
+// every reservation if no flag is set.
+func list(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	tripID := flags.String("trip", "", "only list the reservations made on the trip with this ID")
+	userID := flags.String("user", "", "only list the reservations made by the user with this ID")
+	status := flags.String("status", "", "only list the reservations with this status")
+	flags.Parse(args)
+
+	reservations, err := a.Reservations.List(ctx, &reservation.Criteria{
+		TripID: entity.NewIDFromHex(*tripID),
+		UserID: entity.NewIDFromHex(*userID),
+		Status: entity.Status(*status),
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.out.reservations(reservations)
+}
+
+// cancel cancels the reservations with the given IDs as if their passengers
+// had, and prints the cancelled reservations. The cancellation policy is
+// applied and the events are published.
+func cancel(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("cancel", flag.ExitOnError)
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("cancel: missing reservation ID")
+	}
+
+	return eachReservation(a, flags.Args(), "cancel", func(ID entity.ID) (*entity.Reservation, error) {
+		return a.Reservations.CancelAsPassenger(ctx, ID)
+	})
+}
+
+// forceDelete removes the reservations with the given IDs from the database,
+// whatever their status, and prints the removed reservations.
+func forceDelete(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("force-delete", flag.ExitOnError)
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("force-delete: missing reservation ID")
+	}
+
+	return eachReservation(a, flags.Args(), "force-delete", func(ID entity.ID) (*entity.Reservation, error) {
+		return a.Reservations.ForceDelete(ctx, ID)
+	})
+}
+
+// eachReservation applies an operation to the reservations with the given IDs
+// and prints the reservations it succeeded for. A failure does not stop the
+// operation from being applied to the other reservations.
+func eachReservation(a *app, args []string, name string, op func(ID entity.ID) (*entity.Reservation, error)) error {
+	reservations := []*entity.Reservation{}
+	failed := 0
+	for _, arg := range args {
+		res, err := op(entity.NewIDFromHex(arg))
+		if err != nil {
+			log.Printf("%s: reservation \"%s\": %s", name, arg, err)
+			failed++
+			continue
+		}
+
+		reservations = append(reservations, res)
+	}
+
+	err := a.out.reservations(reservations)
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%s: failed for %d of %d reservation(s)", name, failed, len(args))
+	}
+
+	return nil
+}
+
+// replay queues the webhook deliveries with the given IDs again, or the oldest
+// dead deliveries with the -dead flag, and prints the new deliveries.
+func replay(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	dead := flags.Bool("dead", false, "replay the oldest dead deliveries")
+	limit := flags.Int("limit", webhook.DefaultDeliveriesLimit, "maximum number of dead deliveries to replay")
+	flags.Parse(args)
+
+	if *dead {
+		if flags.NArg() > 0 {
+			return errors.New("replay: delivery IDs cannot be given with -dead")
+		}
+
+		deliveries, err := a.Webhooks.ReplayDead(*limit)
+		if err != nil {
+			return err
+		}
+
+		return a.out.deliveries(deliveries)
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("replay: missing delivery ID")
+	}
+
+	deliveries := []*entity.Delivery{}
+	for _, arg := range flags.Args() {
+		d, err := a.Webhooks.Replay(entity.NewIDFromHex(arg))
+		if err != nil {
+			return err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return a.out.deliveries(deliveries)
+}
+
+// migrate applies the migrations that were not applied yet, and prints their
+// versions.
+func migrate(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Parse(args)
+
+	versions, err := a.db.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.out.migrations(versions)
+}
+
+// reconcile reconciles the reservations made on the trips with the given IDs,
+// or on every trip if none are given, and prints the report. The
+// discrepancies are only repaired when the -repair flag is set.
+func reconcile(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	repair := flags.Bool("repair", false, "repair the discrepancies instead of only reporting them")
+	flags.Parse(args)
+
+	var report *reconciliation.Report
+	var err error
+	if flags.NArg() > 0 {
+		tripIDs := []entity.ID{}
+		for _, arg := range flags.Args() {
+			tripIDs = append(tripIDs, entity.NewIDFromHex(arg))
+		}
+
+		report, err = a.Reconciliation.Reconcile(tripIDs, *repair)
+	} else {
+		report, err = a.Reconciliation.ReconcileAll(*repair)
+	}
+	if err != nil {
+		return err
+	}
+
+	return a.out.report(report)
+}
+
+// export writes the reservations created in a date range, made on a trip, by
+// a user or with a status, or every reservation if no filter is set, to the
+// standard output as CSV or JSON Lines.
+func export(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", string(reservation.FormatCSV), "format of the reservations, csv or jsonl")
+	from := flags.String("from", "", "only export the reservations created at or after this date or date and time (RFC 3339)")
+	to := flags.String("to", "", "only export the reservations created before this date or date and time (RFC 3339)")
+	tripID := flags.String("trip", "", "only export the reservations made on the trip with this ID")
+	userID := flags.String("user", "", "only export the reservations made by the user with this ID")
+	status := flags.String("status", "", "only export the reservations with this status")
+	flags.Parse(args)
+
+	c := &reservation.Criteria{
+		TripID: entity.NewIDFromHex(*tripID),
+		UserID: entity.NewIDFromHex(*userID),
+		Status: entity.Status(*status),
+	}
+
+	var err error
+	if *from != "" {
+		c.CreatedFrom, err = reservation.ParseTime(*from)
+		if err != nil {
+			return fmt.Errorf("export: -from must be an RFC 3339 date or date and time (%s)", err)
+		}
+	}
+	if *to != "" {
+		c.CreatedTo, err = reservation.ParseTime(*to)
+		if err != nil {
+			return fmt.Errorf("export: -to must be an RFC 3339 date or date and time (%s)", err)
+		}
+	}
+
+	n, err := a.Reservations.Export(ctx, c, reservation.Format(*format), os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("export: exported %d reservation(s)", n)
+
+	return nil
+}
+
+// importReservations imports the reservations of a file, or of the standard
+// input, in CSV or JSON Lines, and prints the import report. With the -dry-run
+// flag, the reservations are only validated.
+func importReservations(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", string(reservation.FormatCSV), "format of the reservations, csv or jsonl")
+	dryRun := flags.Bool("dry-run", false, "only validate the reservations")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		return errors.New("import: only one file can be imported at once")
+	}
+
+	in := os.Stdin
+	if flags.NArg() == 1 {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	report, err := a.Reservations.Import(ctx, reservation.Format(*format), in, *dryRun)
+	if report != nil {
+		printErr := a.out.importReport(report)
+		if printErr != nil {
+			return printErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("import: %d of %d reservation(s) were not imported", report.Failed, report.Lines)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Command reservationctl operates the reservation store: it retrieves, lists,
+// cancels and removes reservations, replays webhook deliveries, and runs the
+// migrations and reconciliations, through the same services as the
+// reservation service.
+//
+// It is configured with the same environment variables as the service.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"azure.com/ecovo/reservation-service/cmd/env"
+	"azure.com/ecovo/reservation-service/cmd/setup"
+	"azure.com/ecovo/reservation-service/pkg/db"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+	"github.com/google/uuid"
+)
+
+// An app contains the services the commands operate through, and how they
+// print their results.
+type app struct {
+	*setup.Services
+
+	db  *db.DB
+	out *printer
+}
+
+// A command is a subcommand of reservationctl.
+type command struct {
+	usage   string
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]*command{
+	"get":          {"get ID...", "Prints reservations", get},
+	"list":         {"list [-trip ID] [-user ID] [-status STATUS]", "Lists reservations", list},
+	"cancel":       {"cancel ID...", "Cancels reservations, applying the cancellation policy", cancel},
+	"force-delete": {"force-delete ID...", "Removes reservations from the database, whatever their status", forceDelete},
+	"replay":       {"replay [-dead] [-limit N] [DELIVERY_ID...]", "Queues webhook deliveries again", replay},
+	"migrate":      {"migrate", "Applies the migrations that were not applied yet", migrate},
+	"reconcile":    {"reconcile [-repair] [TRIP_ID...]", "Reconciles reservations with the trip-service", reconcile},
+	"export":       {"export [-format csv|jsonl] [FILTERS]", "Writes reservations to the standard output (-h lists the filters)", export},
+	"import":       {"import [-format csv|jsonl] [-dry-run] [FILE]", "Imports reservations from a file or the standard input", importReservations},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("reservationctl: ")
+
+	flag.Usage = usage
+	format := flag.String("o", formatTable, "output format, json or table")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		log.Printf("unknown command \"%s\"", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	out, err := newPrinter(os.Stdout, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	a, err := newApp(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Every command has its own request ID, so that the audit entries and the
+	// events it causes can be traced back to it.
+	ctx := context.WithValue(context.Background(), requestid.RequestIDContextKey, uuid.New().String())
+
+	err = cmd.run(ctx, a, flag.Args()[1:])
+	a.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: reservationctl [-o json|table] COMMAND [ARGS]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-48s %s\n", commands[name].usage, commands[name].summary)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// newApp connects to the database and creates the services, configured from
+// the environment like the reservation service. The events caused by the
+// commands are published like the service's.
+func newApp(out *printer) (*app, error) {
+	db, err := db.New(env.DB())
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := setup.New(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app{services, db, out}, nil
+}
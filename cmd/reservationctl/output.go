@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reconciliation"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+)
+
+const (
+	// formatJSON prints results as indented JSON, the same as the REST API's
+	// responses.
+	formatJSON = "json"
+
+	// formatTable prints results as tables aligned in columns.
+	formatTable = "table"
+)
+
+// A printer prints the results of the commands in an output format.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// newPrinter creates a printer that writes to the writer in the given format.
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	if format != formatJSON && format != formatTable {
+		return nil, fmt.Errorf("unknown output format \"%s\" (must be json or table)", format)
+	}
+
+	return &printer{w, format}, nil
+}
+
+// print prints the value as JSON, or as a table whose rows are written by the
+// given function otherwise.
+func (p *printer) print(v interface{}, header []string, rows func(tw io.Writer)) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	rows(tw)
+
+	return tw.Flush()
+}
+
+func (p *printer) reservations(reservations []*entity.Reservation) error {
+	header := []string{"ID", "TRIP", "USER", "SEATS", "STATUS", "FARE", "PAYMENT", "VERSION"}
+
+	return p.print(reservations, header, func(tw io.Writer) {
+		for _, r := range reservations {
+			fare := "-"
+			if r.Fare != nil {
+				fare = fmt.Sprintf("%d %s", r.Fare.Amount, r.Fare.Currency)
+			}
+			payment := "-"
+			if r.Payment != nil {
+				payment = string(r.Payment.Status)
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%d\n", r.ID, r.TripID, r.UserID, r.Seats, r.Status, fare, payment, r.Version)
+		}
+	})
+}
+
+func (p *printer) deliveries(deliveries []*entity.Delivery) error {
+	header := []string{"ID", "WEBHOOK", "EVENT", "TYPE", "STATUS", "ATTEMPTS", "NEXT ATTEMPT"}
+
+	return p.print(deliveries, header, func(tw io.Writer) {
+		for _, d := range deliveries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", d.ID, d.WebhookID, d.EventID, d.EventType, d.Status, len(d.Attempts), d.NextAttemptAt.Format(time.RFC3339))
+		}
+	})
+}
+
+// A migrationsResult is the result of applying the migrations.
+type migrationsResult struct {
+	Applied []int `json:"applied"`
+}
+
+func (p *printer) migrations(versions []int) error {
+	header := []string{"APPLIED VERSION"}
+
+	return p.print(migrationsResult{versions}, header, func(tw io.Writer) {
+		for _, v := range versions {
+			fmt.Fprintf(tw, "%d\n", v)
+		}
+	})
+}
+
+func (p *printer) importReport(r *reservation.ImportReport) error {
+	header := []string{"LINE", "ID", "ERROR"}
+
+	err := p.print(r, header, func(tw io.Writer) {
+		for _, e := range r.Errors {
+			id := e.ID.Hex()
+			if id == "" {
+				id = "-"
+			}
+
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", e.Line, id, e.Err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if p.format == formatTable {
+		verb := "imported"
+		if r.DryRun {
+			verb = "valid"
+		}
+		_, err = fmt.Fprintf(p.w, "\n%d of %d reservation(s) %s\n", r.Imported, r.Lines, verb)
+	}
+
+	return err
+}
+
+func (p *printer) report(r *reconciliation.Report) error {
+	header := []string{"TRIP", "RESERVATION", "KIND", "STATUS", "SEATS", "TRIP SEATS", "REPAIRED", "ERROR"}
+
+	return p.print(r, header, func(tw io.Writer) {
+		for _, d := range r.Discrepancies {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%t\t%s\n", d.TripID, d.ReservationID, d.Kind, d.Status, d.Seats, d.TripSeats, d.Repaired, d.Error)
+		}
+		for _, e := range r.Errors {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\t-\t-\t%s\n", e.TripID, e.Error)
+		}
+	})
+}
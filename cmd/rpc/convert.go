@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	pb "azure.com/ecovo/reservation-service/pkg/reservationpb"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// toReservation converts a reservation to its protocol buffer message.
+func toReservation(res *entity.Reservation) *pb.Reservation {
+	m := &pb.Reservation{
+		Id:            res.ID.Hex(),
+		TripId:        res.TripID.Hex(),
+		UserId:        res.UserID.Hex(),
+		SourceId:      res.SourceID.Hex(),
+		DestinationId: res.DestinationID.Hex(),
+		Seats:         int32(res.Seats),
+		Status:        string(res.Status),
+		Version:       int32(res.Version),
+		HoldId:        res.HoldID.Hex(),
+	}
+
+	if res.Fare != nil {
+		m.Fare = &pb.Fare{Amount: int64(res.Fare.Amount), Currency: res.Fare.Currency}
+	}
+
+	if res.Payment != nil {
+		m.Payment = &pb.Payment{
+			Status:   string(res.Payment.Status),
+			Captured: int64(res.Payment.Captured),
+			Refunded: int64(res.Payment.Refunded),
+		}
+	}
+
+	if res.Cancellation != nil {
+		m.Cancellation = &pb.Cancellation{
+			Rule:        res.Cancellation.Rule,
+			Fee:         int64(res.Cancellation.Fee),
+			Reason:      res.Cancellation.Reason,
+			CancelledAt: toTimestamp(res.Cancellation.CancelledAt),
+		}
+	}
+
+	if res.CheckIn != nil {
+		m.CheckIn = &pb.CheckIn{
+			Method:      string(res.CheckIn.Method),
+			Point:       toPoint(res.CheckIn.Point),
+			CheckedInAt: toTimestamp(res.CheckIn.CheckedInAt),
+		}
+	}
+
+	return m
+}
+
+// toReservations converts reservations to their protocol buffer messages.
+func toReservations(rs []*entity.Reservation) []*pb.Reservation {
+	ms := make([]*pb.Reservation, len(rs))
+	for i, res := range rs {
+		ms[i] = toReservation(res)
+	}
+
+	return ms
+}
+
+// toPoint converts a point to its protocol buffer message.
+func toPoint(p *entity.Point) *pb.Point {
+	if p == nil {
+		return nil
+	}
+
+	return &pb.Point{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+// fromPoint converts a point's protocol buffer message to a point.
+func fromPoint(m *pb.Point) *entity.Point {
+	if m == nil {
+		return nil
+	}
+
+	return &entity.Point{Latitude: m.Latitude, Longitude: m.Longitude}
+}
+
+// toTimestamp converts a time to its protocol buffer message.
+func toTimestamp(t time.Time) *timestamp.Timestamp {
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
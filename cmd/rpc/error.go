@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"azure.com/ecovo/reservation-service/cmd/handler"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorCodeKey represents the trailer metadata key whose value is the stable
+// error code of a call that failed (see handler.ErrorCode).
+const ErrorCodeKey = "x-error-code"
+
+// statusCodes maps the codes of the errors the service can respond with to gRPC
+// status codes.
+var statusCodes = map[handler.ErrorCode]codes.Code{
+	handler.CodeValidationFailed:        codes.InvalidArgument,
+	handler.CodeMalformedJSON:           codes.InvalidArgument,
+	handler.CodeUnknownField:            codes.InvalidArgument,
+	handler.CodeBodyTooLarge:            codes.InvalidArgument,
+	handler.CodeUnsupportedMediaType:    codes.InvalidArgument,
+	handler.CodeNotAcceptable:           codes.InvalidArgument,
+	handler.CodeInvalidSegment:          codes.InvalidArgument,
+	handler.CodeInvalidBoardingPass:     codes.InvalidArgument,
+	handler.CodeUnauthorized:            codes.Unauthenticated,
+	handler.CodePaymentDeclined:         codes.FailedPrecondition,
+	handler.CodeForbidden:               codes.PermissionDenied,
+	handler.CodeReservationNotFound:     codes.NotFound,
+	handler.CodeHoldNotFound:            codes.NotFound,
+	handler.CodeWebhookNotFound:         codes.NotFound,
+	handler.CodeTripNotFound:            codes.NotFound,
+	handler.CodeReservationExists:       codes.AlreadyExists,
+	handler.CodeConcurrentModification:  codes.Aborted,
+	handler.CodeInvalidStatus:           codes.FailedPrecondition,
+	handler.CodeBoardingPassNotIssuable: codes.FailedPrecondition,
+	handler.CodeBatchItemSkipped:        codes.Aborted,
+	handler.CodePreconditionFailed:      codes.FailedPrecondition,
+	handler.CodeCheckInNotAllowed:       codes.FailedPrecondition,
+	handler.CodeUserBlocked:             codes.FailedPrecondition,
+	handler.CodeTooManyActive:           codes.FailedPrecondition,
+	handler.CodeTooManyCancellations:    codes.FailedPrecondition,
+	handler.CodeOverlappingReservation:  codes.FailedPrecondition,
+	handler.CodeInternal:                codes.Internal,
+}
+
+// wrapError wraps the given error in a gRPC status, using the same error codes
+// as the REST API. The fields of a validation error are described in a
+// BadRequest detail.
+func wrapError(err error) (*status.Status, *handler.Error) {
+	handlerErr := handler.WrapError(err)
+
+	code, ok := statusCodes[handlerErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, handlerErr.Detail)
+	if len(handlerErr.InvalidParams) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, p := range handlerErr.InvalidParams {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       p.Name,
+				Description: p.Reason,
+			})
+		}
+
+		withDetails, detailsErr := st.WithDetails(br)
+		if detailsErr == nil {
+			st = withDetails
+		}
+	}
+
+	return st, handlerErr
+}
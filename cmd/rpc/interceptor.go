@@ -0,0 +1,159 @@
+package rpc
+
+import (
+	"context"
+	"log"
+
+	"azure.com/ecovo/reservation-service/cmd/middleware/auth"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey represents the metadata key of a call's request ID.
+const RequestIDKey = "x-request-id"
+
+// UnaryInterceptors returns the interceptors of unary calls, in order: request
+// ID propagation, error handling and authentication with the given validators.
+func UnaryInterceptors(validators map[string]auth.Validator) []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		unaryRequestID,
+		unaryErrors,
+		unaryAuth(validators),
+	}
+}
+
+// StreamInterceptors returns the interceptors of streaming calls, in the same
+// order as the ones of unary calls.
+func StreamInterceptors(validators map[string]auth.Validator) []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		streamRequestID,
+		streamErrors,
+		streamAuth(validators),
+	}
+}
+
+// withRequestID extracts the request ID from a call's metadata, if it is
+// present, and stores it in the context. If no request ID is present, it will
+// be generated.
+func withRequestID(ctx context.Context) (context.Context, string) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(RequestIDKey)) > 0 {
+		requestID = md.Get(RequestIDKey)[0]
+	}
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	return context.WithValue(ctx, requestid.RequestIDContextKey, requestID), requestID
+}
+
+// withUserInfo validates the authorization in a call's metadata using the
+// given validators, and stores the authenticated user's information in the
+// context.
+func withUserInfo(ctx context.Context, validators map[string]auth.Validator) (context.Context, error) {
+	header := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		header = md.Get("authorization")[0]
+	}
+
+	userInfo, err := auth.Authenticate(validators, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return context.WithValue(ctx, identity.UserInfoContextKey, userInfo), nil
+}
+
+// toStatus logs an error that occurred while handling a call and converts it to
+// a gRPC status error. The stable error code is returned with it, if there is
+// one.
+func toStatus(ctx context.Context, err error) (string, error) {
+	if _, ok := status.FromError(err); ok {
+		return "", err
+	}
+
+	st, handlerErr := wrapError(err)
+
+	requestID, _ := requestid.FromContext(ctx)
+	log.Printf("[Request ID=%s] error: %s", requestID, handlerErr)
+
+	return string(handlerErr.Code), st.Err()
+}
+
+func unaryRequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, requestID := withRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+	return handler(ctx, req)
+}
+
+func unaryErrors(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	code, err := toStatus(ctx, err)
+	if code != "" {
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(ErrorCodeKey, code))
+	}
+
+	return nil, err
+}
+
+func unaryAuth(validators map[string]auth.Validator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := withUserInfo(ctx, validators)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// A serverStream is a server stream whose context was replaced.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamRequestID(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, requestID := withRequestID(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(RequestIDKey, requestID))
+
+	return handler(srv, &serverStream{ss, ctx})
+}
+
+func streamErrors(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	if err == nil {
+		return nil
+	}
+
+	code, err := toStatus(ss.Context(), err)
+	if code != "" {
+		ss.SetTrailer(metadata.Pairs(ErrorCodeKey, code))
+	}
+
+	return err
+}
+
+func streamAuth(validators map[string]auth.Validator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := withUserInfo(ss.Context(), validators)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ss, ctx})
+	}
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"context"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	pb "azure.com/ecovo/reservation-service/pkg/reservationpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A Server serves the reservation API over gRPC. It uses the same use case as
+// the REST handlers, so both APIs behave the same way.
+type Server struct {
+	service reservation.UseCase
+}
+
+// NewServer creates a gRPC server of the reservation API.
+func NewServer(service reservation.UseCase) *Server {
+	return &Server{service}
+}
+
+// CreateReservation books seats on a trip for the authenticated user. The
+// user ID of the request can be left out, but it must be theirs when it is
+// given.
+func (s *Server) CreateReservation(ctx context.Context, req *pb.CreateReservationRequest) (*pb.Reservation, error) {
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil || userInfo.SubID == "" {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	if req.UserId != "" && req.UserId != userInfo.SubID {
+		return nil, status.Error(codes.PermissionDenied, "reservations can only be booked for the authenticated user")
+	}
+
+	res, err := s.service.Register(ctx, &entity.Reservation{
+		TripID:        entity.NewIDFromHex(req.TripId),
+		UserID:        entity.NewIDFromHex(userInfo.SubID),
+		SourceID:      entity.NewIDFromHex(req.SourceId),
+		DestinationID: entity.NewIDFromHex(req.DestinationId),
+		Seats:         int(req.Seats),
+		HoldID:        entity.NewIDFromHex(req.HoldId),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReservation(res), nil
+}
+
+// GetReservation retrieves a reservation by its ID, for its passenger or the
+// driver of its trip.
+func (s *Server) GetReservation(ctx context.Context, req *pb.GetReservationRequest) (*pb.Reservation, error) {
+	res, err := s.service.FindForUser(ctx, entity.NewIDFromHex(req.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	return toReservation(res), nil
+}
+
+// ListReservations lists the reservations of a trip, for its driver.
+func (s *Server) ListReservations(ctx context.Context, req *pb.ListReservationsRequest) (*pb.ListReservationsResponse, error) {
+	rs, err := s.service.FindByTripID(ctx, entity.NewIDFromHex(req.TripId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListReservationsResponse{Reservations: toReservations(rs)}, nil
+}
+
+// ModifyReservation accepts, rejects or checks in a reservation.
+func (s *Server) ModifyReservation(ctx context.Context, req *pb.ModifyReservationRequest) (*pb.Reservation, error) {
+	id := entity.NewIDFromHex(req.Id)
+	tripID := entity.NewIDFromHex(req.TripId)
+	version := int(req.Version)
+
+	var res *entity.Reservation
+	var err error
+	switch m := req.Modification.(type) {
+	case *pb.ModifyReservationRequest_Accept:
+		res, err = s.service.Accept(ctx, tripID, id, version)
+	case *pb.ModifyReservationRequest_Reject:
+		res, err = s.service.Reject(ctx, tripID, id, m.Reject.GetReason(), version)
+	case *pb.ModifyReservationRequest_CheckIn:
+		res, err = s.service.CheckIn(ctx, tripID, id, m.CheckIn.GetCode(), version)
+	case *pb.ModifyReservationRequest_SelfCheckIn:
+		res, err = s.service.SelfCheckIn(ctx, id, fromPoint(m.SelfCheckIn.GetPoint()), version)
+	default:
+		err = entity.NewFieldValidationError("modification", "is missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toReservation(res), nil
+}
+
+// CancelReservation cancels a reservation as its passenger or, when the trip's
+// ID is given, as the driver of its trip.
+func (s *Server) CancelReservation(ctx context.Context, req *pb.CancelReservationRequest) (*pb.Reservation, error) {
+	id := entity.NewIDFromHex(req.Id)
+	version := int(req.Version)
+
+	var res *entity.Reservation
+	var err error
+	if req.TripId != "" {
+		res, err = s.service.CancelByDriver(ctx, entity.NewIDFromHex(req.TripId), id, req.Reason, version)
+	} else if _, err = s.service.FindForUser(ctx, id); err == nil {
+		res, err = s.service.Delete(ctx, id, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toReservation(res), nil
+}
+
+// WatchReservation streams a reservation, once when the call starts and then
+// every time it changes, until the call is cancelled.
+func (s *Server) WatchReservation(req *pb.WatchReservationRequest, stream pb.ReservationService_WatchReservationServer) error {
+	ctx := stream.Context()
+	id := entity.NewIDFromHex(req.Id)
+
+	sub, err := s.service.Watch(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	res, err := s.service.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(toReservation(res))
+	if err != nil {
+		return err
+	}
+
+	version := res.Version
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case c, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "too many changes were missed, watch the reservation again")
+			}
+			if c.Reservation.Version <= version {
+				continue
+			}
+
+			err = stream.Send(toReservation(c.Reservation))
+			if err != nil {
+				return err
+			}
+			version = c.Reservation.Version
+		}
+	}
+}
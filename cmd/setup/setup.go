@@ -0,0 +1,125 @@
+// Package setup creates the services of the reservation service, configured
+// from the environment, so that the service and its tools are wired the same
+// way.
+package setup
+
+import (
+	"azure.com/ecovo/reservation-service/cmd/env"
+	"azure.com/ecovo/reservation-service/pkg/audit"
+	"azure.com/ecovo/reservation-service/pkg/booking"
+	"azure.com/ecovo/reservation-service/pkg/cancellation"
+	"azure.com/ecovo/reservation-service/pkg/checkin"
+	"azure.com/ecovo/reservation-service/pkg/db"
+	"azure.com/ecovo/reservation-service/pkg/event"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"azure.com/ecovo/reservation-service/pkg/payment"
+	"azure.com/ecovo/reservation-service/pkg/pricing"
+	"azure.com/ecovo/reservation-service/pkg/reconciliation"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+	"azure.com/ecovo/reservation-service/pkg/webhook"
+)
+
+// Services contains the services of the reservation service.
+type Services struct {
+	Trips          trip.UseCase
+	Pricing        pricing.UseCase
+	Holds          hold.UseCase
+	Reservations   reservation.UseCase
+	Webhooks       webhook.UseCase
+	Audit          audit.UseCase
+	Reconciliation reconciliation.UseCase
+
+	// WebhookRepository is the repository the webhook deliveries are
+	// dispatched from.
+	WebhookRepository webhook.Repository
+
+	// natsPublisher is nil unless events are published to NATS.
+	natsPublisher *event.NATSPublisher
+}
+
+// New creates the services, which store their data in the database. The events
+// are delivered to the webhooks, and published to NATS when it is configured.
+func New(db *db.DB) (*Services, error) {
+	tripRepository, err := trip.NewRestRepository(env.TripServiceDomain(), env.AuthCredentials())
+	if err != nil {
+		return nil, err
+	}
+	tripUseCase := trip.NewService(tripRepository)
+
+	pricingUseCase := pricing.NewService(tripUseCase)
+
+	reservationRepository, err := reservation.NewMongoRepository(db.Reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	holdRepository, err := hold.NewMongoRepository(db.Holds)
+	if err != nil {
+		return nil, err
+	}
+	holdUseCase := hold.NewService(holdRepository, tripUseCase, env.HoldTTL())
+
+	// No payment processor is integrated yet, so payments are only logged.
+	paymentProvider := payment.NopProvider{}
+
+	webhookRepository, err := webhook.NewMongoRepository(db.Webhooks, db.WebhookDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	webhookUseCase := webhook.NewService(webhookRepository)
+
+	var natsPublisher *event.NATSPublisher
+	eventPublisher := event.MultiPublisher{webhookUseCase}
+	if url := env.NATSURL(); url != "" {
+		natsPublisher, err = event.NewNATSPublisher(url)
+		if err != nil {
+			return nil, err
+		}
+
+		eventPublisher = append(eventPublisher, natsPublisher)
+	}
+
+	auditRepository, err := audit.NewMongoRepository(db.AuditLog)
+	if err != nil {
+		return nil, err
+	}
+	auditUseCase := audit.NewService(auditRepository)
+
+	cancellationPolicy, err := cancellation.NewPolicy(env.Cancellation())
+	if err != nil {
+		return nil, err
+	}
+
+	checkInPolicy, err := checkin.NewPolicy(env.CheckIn())
+	if err != nil {
+		return nil, err
+	}
+
+	bookingPolicy, err := booking.NewPolicy(env.Booking())
+	if err != nil {
+		return nil, err
+	}
+
+	reservationUseCase := reservation.NewService(reservationRepository, tripUseCase, pricingUseCase, holdUseCase, paymentProvider, cancellationPolicy, checkInPolicy, bookingPolicy, eventPublisher, auditUseCase)
+
+	return &Services{
+		Trips:             tripUseCase,
+		Pricing:           pricingUseCase,
+		Holds:             holdUseCase,
+		Reservations:      reservationUseCase,
+		Webhooks:          webhookUseCase,
+		Audit:             auditUseCase,
+		Reconciliation:    reconciliation.NewService(reservationRepository, holdRepository, tripUseCase),
+		WebhookRepository: webhookRepository,
+		natsPublisher:     natsPublisher,
+	}, nil
+}
+
+// Close closes the connections that must be closed before exiting, so that
+// the events that were published are not lost.
+func (s *Services) Close() {
+	if s.natsPublisher != nil {
+		s.natsPublisher.Close()
+	}
+}
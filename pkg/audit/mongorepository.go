@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/options"
+)
+
+// A MongoRepository is a repository that appends audit entries to a MongoDB
+// collection.
+type MongoRepository struct {
+	collection *mongo.Collection
+}
+
+type document struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	ReservationID primitive.ObjectID `bson:"reservationId"`
+	Operation     string             `bson:"operation"`
+	Actor         actorDocument      `bson:"actor"`
+	RequestID     string             `bson:"requestId"`
+	Changes       []changeDocument   `bson:"changes"`
+	Timestamp     time.Time          `bson:"timestamp"`
+}
+
+type actorDocument struct {
+	Kind  string `bson:"kind"`
+	SubID string `bson:"subId"`
+	Email string `bson:"email"`
+}
+
+type changeDocument struct {
+	Field  string      `bson:"field"`
+	Before interface{} `bson:"before"`
+	After  interface{} `bson:"after"`
+}
+
+func newDocumentFromEntity(e *entity.AuditEntry) (*document, error) {
+	if e == nil {
+		return nil, fmt.Errorf("audit.MongoRepository: entity is nil")
+	}
+
+	reservationID, err := primitive.ObjectIDFromHex(e.ReservationID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("audit.MongoRepository: failed to create object ID")
+	}
+
+	d := &document{
+		ReservationID: reservationID,
+		Operation:     e.Operation,
+		RequestID:     e.RequestID,
+		Changes:       make([]changeDocument, 0, len(e.Changes)),
+		Timestamp:     e.Timestamp,
+	}
+
+	if e.Actor != nil {
+		d.Actor = actorDocument{
+			Kind:  string(e.Actor.Kind),
+			SubID: e.Actor.SubID,
+			Email: e.Actor.Email,
+		}
+	}
+
+	for _, c := range e.Changes {
+		d.Changes = append(d.Changes, changeDocument{
+			Field:  c.Field,
+			Before: c.Before,
+			After:  c.After,
+		})
+	}
+
+	return d, nil
+}
+
+func (d document) Entity() *entity.AuditEntry {
+	e := &entity.AuditEntry{
+		ID:            entity.NewIDFromHex(d.ID.Hex()),
+		ReservationID: entity.NewIDFromHex(d.ReservationID.Hex()),
+		Operation:     d.Operation,
+		Actor: &entity.Actor{
+			Kind:  entity.ActorKind(d.Actor.Kind),
+			SubID: d.Actor.SubID,
+			Email: d.Actor.Email,
+		},
+		RequestID: d.RequestID,
+		Changes:   make([]*entity.Change, 0, len(d.Changes)),
+		Timestamp: d.Timestamp,
+	}
+
+	for _, c := range d.Changes {
+		e.Changes = append(e.Changes, &entity.Change{
+			Field:  c.Field,
+			Before: c.Before,
+			After:  c.After,
+		})
+	}
+
+	return e
+}
+
+// NewMongoRepository creates an audit repository for a MongoDB collection.
+func NewMongoRepository(collection *mongo.Collection) (Repository, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("audit.MongoRepository: collection is nil")
+	}
+
+	return &MongoRepository{collection}, nil
+}
+
+// FindByReservationID retrieves the entries recorded for the reservation with
+// the given ID, from the oldest to the newest.
+func (r *MongoRepository) FindByReservationID(reservationID entity.ID) ([]*entity.AuditEntry, error) {
+	objectID, err := primitive.ObjectIDFromHex(reservationID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("audit.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "reservationId", Value: objectID}}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+
+	cur, err := r.collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("audit.MongoRepository: failed to find entries (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	entries := []*entity.AuditEntry{}
+	for cur.Next(context.TODO()) {
+		var d document
+		err := cur.Decode(&d)
+		if err != nil {
+			return nil, fmt.Errorf("audit.MongoRepository: failed to decode entry (%s)", err)
+		}
+
+		entries = append(entries, d.Entity())
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("audit.MongoRepository: failed to find entries (%s)", err)
+	}
+
+	return entries, nil
+}
+
+// Append stores the new entry in the database and returns the unique
+// identifier that was generated for it.
+func (r *MongoRepository) Append(e *entity.AuditEntry) (entity.ID, error) {
+	d, err := newDocumentFromEntity(e)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("audit.MongoRepository: failed to create entry document from entity (%s)", err)
+	}
+
+	resp, err := r.collection.InsertOne(context.TODO(), d)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("audit.MongoRepository: failed to append entry (%s)", err)
+	}
+
+	ID, ok := resp.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return entity.NilID, fmt.Errorf("audit.MongoRepository: failed to get ID of appended entry")
+	}
+
+	return entity.ID(ID.Hex()), nil
+}
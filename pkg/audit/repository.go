@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Repository is an interface representing the ability to append entries to an
+// audit log and to read them back. Entries are never modified nor removed.
+type Repository interface {
+	FindByReservationID(reservationID entity.ID) ([]*entity.AuditEntry, error)
+	Append(e *entity.AuditEntry) (entity.ID, error)
+}
@@ -0,0 +1,209 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+)
+
+const (
+	// OperationCreate is the operation recorded when a reservation is
+	// booked.
+	OperationCreate = "create"
+
+	// OperationCancel is the operation recorded when a reservation is
+	// cancelled.
+	OperationCancel = "cancel"
+
+	// OperationAccept is the operation recorded when a reservation is
+	// accepted by the driver.
+	OperationAccept = "accept"
+
+	// OperationReject is the operation recorded when a reservation is
+	// rejected by the driver.
+	OperationReject = "reject"
+
+	// OperationCancelByDriver is the operation recorded when a reservation
+	// is cancelled by the driver.
+	OperationCancelByDriver = "cancel-by-driver"
+
+	// OperationCancelTrip is the operation recorded when a reservation is
+	// cancelled because its trip was cancelled.
+	OperationCancelTrip = "cancel-trip"
+
+	// OperationCheckIn is the operation recorded when a passenger checks
+	// in.
+	OperationCheckIn = "check-in"
+
+	// OperationNoShow is the operation recorded when a passenger is marked as
+	// a no-show.
+	OperationNoShow = "no-show"
+
+	// OperationComplete is the operation recorded when a confirmed
+	// reservation is completed and its payment is captured.
+	OperationComplete = "complete"
+
+	// OperationExpire is the operation recorded when a pending reservation
+	// expires because its trip was completed.
+	OperationExpire = "expire"
+
+	// OperationRollback is the operation recorded when a reservation is
+	// removed because its passenger could not be told it was booked.
+	OperationRollback = "rollback"
+
+	// OperationForceDelete is the operation recorded when an operator removes
+	// a reservation from the database.
+	OperationForceDelete = "force-delete"
+
+	// OperationImport is the operation recorded when an operator imports a
+	// reservation into the database.
+	OperationImport = "import"
+)
+
+// UseCase is an interface representing the ability to record the operations
+// performed on reservations and to retrieve their history.
+type UseCase interface {
+	Record(ctx context.Context, operation string, before *entity.Reservation, after *entity.Reservation) error
+	FindByReservationID(reservationID entity.ID) ([]*entity.AuditEntry, error)
+}
+
+// A Service handles the business logic related to the audit log.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates an audit service that appends entries to the audit log
+// through a repository.
+func NewService(repo Repository) *Service {
+	return &Service{repo}
+}
+
+// Record appends an entry to the audit log for an operation that changed a
+// reservation from the given state to another. The state before is nil when
+// the reservation was created, and the state after is nil when it was
+// removed.
+//
+// The actor and the request ID are taken from the context.
+func (s *Service) Record(ctx context.Context, operation string, before *entity.Reservation, after *entity.Reservation) error {
+	res := after
+	if res == nil {
+		res = before
+	}
+	if res == nil {
+		return fmt.Errorf("audit.Service: reservation is nil")
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	requestID, _ := requestid.FromContext(ctx)
+
+	e := &entity.AuditEntry{
+		ReservationID: res.ID,
+		Operation:     operation,
+		Actor:         actorFromContext(ctx),
+		RequestID:     requestID,
+		Changes:       changes,
+		Timestamp:     time.Now().UTC(),
+	}
+
+	_, err = s.repo.Append(e)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByReservationID retrieves the history of the reservation with the given
+// ID, from the oldest to the newest entry.
+func (s *Service) FindByReservationID(reservationID entity.ID) ([]*entity.AuditEntry, error) {
+	return s.repo.FindByReservationID(reservationID)
+}
+
+func actorFromContext(ctx context.Context) *entity.Actor {
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil {
+		return &entity.Actor{Kind: entity.ActorSystem}
+	}
+
+	if userInfo.SubID == "" {
+		return &entity.Actor{Kind: entity.ActorService}
+	}
+
+	return &entity.Actor{
+		Kind:  entity.ActorUser,
+		SubID: userInfo.SubID,
+		Email: userInfo.Email,
+	}
+}
+
+// Diff compares the JSON representation of two values and returns the fields
+// whose value changed, sorted by name. Either value can be nil.
+func Diff(before interface{}, after interface{}) ([]*entity.Change, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range b {
+		names = append(names, name)
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []*entity.Change{}
+	for _, name := range names {
+		if !reflect.DeepEqual(b[name], a[name]) {
+			changes = append(changes, &entity.Change{
+				Field:  name,
+				Before: b[name],
+				After:  a[name],
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func fields(v interface{}) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if v == nil {
+		return m, nil
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return m, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("audit: failed to encode value (%s)", err)
+	}
+
+	err = json.Unmarshal(raw, &m)
+	if err != nil {
+		return nil, fmt.Errorf("audit: failed to decode value (%s)", err)
+	}
+
+	return m, nil
+}
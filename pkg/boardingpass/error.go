@@ -0,0 +1,21 @@
+package boardingpass
+
+// An InvalidTokenError is an error that represents that a boarding pass token
+// is malformed or that its signature does not match.
+type InvalidTokenError struct {
+	msg string
+}
+
+func (e InvalidTokenError) Error() string {
+	return e.msg
+}
+
+// A NotIssuableError is an error that represents that no boarding pass can be
+// issued for a reservation, because it is not confirmed.
+type NotIssuableError struct {
+	msg string
+}
+
+func (e NotIssuableError) Error() string {
+	return e.msg
+}
@@ -0,0 +1,118 @@
+package boardingpass
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCodeSize represents the width and height, in pixels, of a boarding pass's
+// QR code.
+const QRCodeSize = 256
+
+// A BoardingPass contains a reservation's signed token, and a QR code that
+// encodes it as a PNG image.
+type BoardingPass struct {
+	ReservationID entity.ID `json:"reservationId"`
+	Token         string    `json:"token"`
+	QRCode        []byte    `json:"qrCode"`
+}
+
+// A Verification is the result of verifying a boarding pass. It contains the
+// claims of the boarding pass and the current status of the reservation.
+type Verification struct {
+	Claims *Claims       `json:"claims"`
+	Status entity.Status `json:"status"`
+}
+
+// UseCase is an interface representing the ability to issue and verify
+// boarding passes.
+type UseCase interface {
+	Issue(ctx context.Context, reservationID entity.ID) (*BoardingPass, error)
+	Verify(ctx context.Context, token string) (*Verification, error)
+	KeySet() *KeySet
+}
+
+// A Service issues boarding passes for reservations and verifies them.
+type Service struct {
+	reservationService reservation.UseCase
+	signer             *Signer
+}
+
+// NewService creates a boarding pass service that signs the boarding passes
+// of the reservations retrieved through the reservation service with the
+// signer.
+func NewService(reservationService reservation.UseCase, signer *Signer) *Service {
+	return &Service{reservationService, signer}
+}
+
+// Issue signs a boarding pass for the confirmed reservation with the given ID,
+// as long as the authenticated user is its passenger, since the boarding pass
+// contains the check-in code.
+func (s *Service) Issue(ctx context.Context, reservationID entity.ID) (*BoardingPass, error) {
+	res, err := s.reservationService.FindByID(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = reservation.AuthorizePassenger(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Status != entity.StatusConfirmed {
+		return nil, NotIssuableError{fmt.Sprintf("boardingpass.Service: reservation with ID \"%s\" is %s", res.ID, res.Status)}
+	}
+
+	token, err := s.signer.Sign(&Claims{
+		ReservationID: res.ID,
+		TripID:        res.TripID,
+		UserID:        res.UserID,
+		SourceID:      res.SourceID,
+		DestinationID: res.DestinationID,
+		Seats:         res.Seats,
+		CheckInCode:   res.CheckInCode,
+		IssuedAt:      time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, QRCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("boardingpass.Service: failed to encode QR code (%s)", err)
+	}
+
+	return &BoardingPass{res.ID, token, png}, nil
+}
+
+// Verify ensures that the token is a boarding pass that was issued by the
+// service, and retrieves the current status of its reservation, since it may
+// have been cancelled after the boarding pass was issued.
+func (s *Service) Verify(ctx context.Context, token string) (*Verification, error) {
+	if token == "" {
+		return nil, entity.NewFieldValidationError("token", "is missing")
+	}
+
+	c, err := s.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.reservationService.FindByID(ctx, c.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verification{c, res.Status}, nil
+}
+
+// KeySet returns the public key that can be used to verify boarding passes
+// offline.
+func (s *Service) KeySet() *KeySet {
+	return s.signer.KeySet()
+}
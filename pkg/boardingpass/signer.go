@@ -0,0 +1,167 @@
+package boardingpass
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Claims contains the information of a reservation that is encoded in a
+// boarding pass.
+type Claims struct {
+	ReservationID entity.ID `json:"sub"`
+	TripID        entity.ID `json:"tripId"`
+	UserID        entity.ID `json:"userId"`
+	SourceID      entity.ID `json:"sourceId"`
+	DestinationID entity.ID `json:"destinationId"`
+	Seats         int       `json:"seats"`
+	CheckInCode   string    `json:"code,omitempty"`
+	IssuedAt      int64     `json:"iat"`
+}
+
+// A JWK is a public key in the JSON Web Key format (RFC 8037), that can be
+// used to verify boarding passes.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+// A KeySet is a set of public keys in the JSON Web Key Set format.
+type KeySet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// algorithm represents the JWS algorithm used to sign boarding passes.
+const algorithm = "EdDSA"
+
+var encoding = base64.RawURLEncoding
+
+// A Signer signs boarding passes as compact JSON Web Signatures, using an
+// Ed25519 key, and verifies them.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner creates a signer from the seed of an Ed25519 private key.
+func NewSigner(seed []byte) (*Signer, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("boardingpass: key seed must be %d bytes long", ed25519.SeedSize)
+	}
+
+	key := ed25519.NewKeyFromSeed(seed)
+	sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+
+	return &Signer{key, encoding.EncodeToString(sum[:8])}, nil
+}
+
+// GenerateSigner creates a signer with a new random key.
+func GenerateSigner() (*Signer, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	if err != nil {
+		return nil, fmt.Errorf("boardingpass: failed to generate key (%s)", err)
+	}
+
+	return NewSigner(seed)
+}
+
+// Sign encodes the claims in a signed token.
+func (s *Signer) Sign(c *Claims) (string, error) {
+	h, err := json.Marshal(header{algorithm, "JWT", s.keyID})
+	if err != nil {
+		return "", fmt.Errorf("boardingpass: failed to encode header (%s)", err)
+	}
+
+	p, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("boardingpass: failed to encode claims (%s)", err)
+	}
+
+	signingInput := encoding.EncodeToString(h) + "." + encoding.EncodeToString(p)
+	signature := ed25519.Sign(s.key, []byte(signingInput))
+
+	return signingInput + "." + encoding.EncodeToString(signature), nil
+}
+
+// Verify ensures that the token was signed by the signer and was not tampered
+// with, and returns its claims.
+func (s *Signer) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, InvalidTokenError{"boardingpass: token is malformed"}
+	}
+
+	var h header
+	err := decodeSegment(parts[0], &h)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Algorithm != algorithm || h.KeyID != s.keyID {
+		return nil, InvalidTokenError{"boardingpass: token was not signed with the boarding pass key"}
+	}
+
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, InvalidTokenError{"boardingpass: token signature is malformed"}
+	}
+
+	if !ed25519.Verify(s.key.Public().(ed25519.PublicKey), []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, InvalidTokenError{"boardingpass: token signature does not match"}
+	}
+
+	var c Claims
+	err = decodeSegment(parts[1], &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// KeySet returns the public key used to verify boarding passes.
+func (s *Signer) KeySet() *KeySet {
+	return &KeySet{
+		Keys: []*JWK{
+			{
+				KeyType:   "OKP",
+				Curve:     "Ed25519",
+				X:         encoding.EncodeToString(s.key.Public().(ed25519.PublicKey)),
+				KeyID:     s.keyID,
+				Use:       "sig",
+				Algorithm: algorithm,
+			},
+		},
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := encoding.DecodeString(segment)
+	if err != nil {
+		return InvalidTokenError{"boardingpass: token is malformed"}
+	}
+
+	err = json.Unmarshal(b, v)
+	if err != nil {
+		return InvalidTokenError{"boardingpass: token is malformed"}
+	}
+
+	return nil
+}
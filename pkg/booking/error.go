@@ -0,0 +1,41 @@
+package booking
+
+// A BlockedError is an error that represents that a user is on the block list,
+// and so cannot book reservations.
+type BlockedError struct {
+	msg string
+}
+
+func (e BlockedError) Error() string {
+	return e.msg
+}
+
+// A TooManyActiveError is an error that represents that a user already holds
+// as many active reservations as they are allowed to.
+type TooManyActiveError struct {
+	msg string
+}
+
+func (e TooManyActiveError) Error() string {
+	return e.msg
+}
+
+// A TooManyCancellationsError is an error that represents that a user
+// cancelled too many reservations recently to book another one.
+type TooManyCancellationsError struct {
+	msg string
+}
+
+func (e TooManyCancellationsError) Error() string {
+	return e.msg
+}
+
+// An OverlapError is an error that represents that a reservation would overlap
+// in time with another active reservation of the same user.
+type OverlapError struct {
+	msg string
+}
+
+func (e OverlapError) Error() string {
+	return e.msg
+}
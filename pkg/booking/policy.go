@@ -0,0 +1,176 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Config contains the information required to configure a booking policy.
+type Config struct {
+	// MaxActive specifies how many active reservations a user can hold at
+	// once. A value of 0 lets a user hold any number of reservations.
+	MaxActive int
+
+	// MaxCancellations specifies how many reservations a user can cancel
+	// within the cancellation window before they can no longer book. A value
+	// of 0 lets a user cancel any number of reservations.
+	MaxCancellations int
+
+	// CancellationWindow specifies how far back the cancellations of a user
+	// are counted.
+	CancellationWindow time.Duration
+
+	// AllowOverlaps specifies whether or not a user can hold reservations
+	// whose rides overlap in time.
+	AllowOverlaps bool
+
+	// BlockedUsers contains the IDs of the users who cannot book.
+	BlockedUsers []entity.ID
+}
+
+const (
+	// DefaultMaxActive represents the default number of active reservations
+	// a user can hold at once.
+	DefaultMaxActive = 5
+
+	// DefaultMaxCancellations represents the default number of reservations
+	// a user can cancel within the cancellation window.
+	DefaultMaxCancellations = 3
+
+	// DefaultCancellationWindow represents the default amount of time during
+	// which the cancellations of a user are counted.
+	DefaultCancellationWindow = 7 * 24 * time.Hour
+)
+
+// Validate looks at the configuration's contents to ensure it has all the
+// required fields.
+func (conf *Config) validate() error {
+	if conf.MaxActive < 0 {
+		return errors.New("maximum of active reservations must be positive")
+	}
+
+	if conf.MaxCancellations < 0 {
+		return errors.New("maximum of cancellations must be positive")
+	}
+
+	if conf.MaxCancellations > 0 && conf.CancellationWindow <= 0 {
+		return errors.New("cancellation window must be positive")
+	}
+
+	return nil
+}
+
+// A Policy determines whether or not a user can book a reservation, given the
+// reservations they already made, to keep them from grabbing seats they do not
+// mean to use.
+type Policy struct {
+	conf    *Config
+	blocked map[entity.ID]bool
+}
+
+// NewPolicy creates a booking policy with the given configuration.
+func NewPolicy(conf *Config) (*Policy, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("booking: missing configuration")
+	}
+
+	err := conf.validate()
+	if err != nil {
+		return nil, fmt.Errorf("booking: configuration %s", err)
+	}
+
+	blocked := make(map[entity.ID]bool, len(conf.BlockedUsers))
+	for _, ID := range conf.BlockedUsers {
+		blocked[ID] = true
+	}
+
+	return &Policy{conf, blocked}, nil
+}
+
+// AllowsBooking ensures that the user with the given ID can book another
+// reservation at the given time, given the reservations they already made.
+func (p *Policy) AllowsBooking(userID entity.ID, reservations []*entity.Reservation, now time.Time) error {
+	if p.blocked[userID] {
+		return BlockedError{fmt.Sprintf("booking.Policy: user \"%s\" is blocked", userID)}
+	}
+
+	active := 0
+	cancelled := 0
+	since := now.Add(-p.conf.CancellationWindow)
+	for _, r := range reservations {
+		if r.IsActive() {
+			active++
+		}
+
+		// Only the cancellations made by the passenger are counted, since
+		// those made by the driver are not up to them.
+		if r.Status == entity.StatusCancelled && r.Cancellation != nil && r.Cancellation.CancelledAt.After(since) {
+			cancelled++
+		}
+	}
+
+	if p.conf.MaxActive > 0 && active >= p.conf.MaxActive {
+		return TooManyActiveError{fmt.Sprintf("booking.Policy: user \"%s\" cannot hold more than %d active reservation(s)", userID, p.conf.MaxActive)}
+	}
+
+	if p.conf.MaxCancellations > 0 && cancelled >= p.conf.MaxCancellations {
+		return TooManyCancellationsError{fmt.Sprintf("booking.Policy: user \"%s\" cancelled %d reservation(s) in the last %s", userID, cancelled, p.conf.CancellationWindow)}
+	}
+
+	return nil
+}
+
+// CancellationWindow returns how far back the cancellations of a user are
+// counted.
+func (p *Policy) CancellationWindow() time.Duration {
+	return p.conf.CancellationWindow
+}
+
+// AllowsOverlaps returns whether or not a user can hold reservations whose
+// rides overlap in time.
+func (p *Policy) AllowsOverlaps() bool {
+	return p.conf.AllowOverlaps
+}
+
+// A Ride is the part of a trip a passenger is on, from the departure from
+// their source to the arrival at their destination.
+type Ride struct {
+	ReservationID entity.ID
+	DepartAt      time.Time
+	ArriveAt      time.Time
+}
+
+// NewRide creates the ride of a reservation on the given trip.
+func NewRide(r *entity.Reservation, t *entity.Trip) *Ride {
+	return &Ride{r.ID, t.DepartureFrom(r.SourceID), t.ArrivalAt(r.DestinationID)}
+}
+
+// Overlaps returns whether or not the rides overlap in time. Rides that only
+// touch, where one arrives as the other departs, do not overlap.
+func (r *Ride) Overlaps(o *Ride) bool {
+	return r.DepartAt.Before(o.ArriveAt) && o.DepartAt.Before(r.ArriveAt)
+}
+
+// VerifyNoOverlap ensures that the ride does not overlap in time with any of
+// the rides the user already booked, unless overlaps are allowed.
+func (p *Policy) VerifyNoOverlap(ride *Ride, booked []*Ride) error {
+	if p.conf.AllowOverlaps {
+		return nil
+	}
+
+	for _, b := range booked {
+		if !ride.Overlaps(b) {
+			continue
+		}
+
+		if b.ReservationID.IsZero() {
+			return OverlapError{"booking.Policy: ride overlaps in time with another reservation being booked"}
+		}
+		return OverlapError{fmt.Sprintf("booking.Policy: ride overlaps in time with reservation \"%s\"", b.ReservationID)}
+	}
+
+	return nil
+}
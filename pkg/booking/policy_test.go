@@ -0,0 +1,146 @@
+package booking
+
+import (
+	"testing"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+func cancelledAt(at time.Time) *entity.Reservation {
+	return &entity.Reservation{
+		Status:       entity.StatusCancelled,
+		Cancellation: &entity.Cancellation{CancelledAt: at},
+	}
+}
+
+func TestAllowsBooking(t *testing.T) {
+	now := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+	p, err := NewPolicy(&Config{
+		MaxActive:          2,
+		MaxCancellations:   2,
+		CancellationWindow: 24 * time.Hour,
+		BlockedUsers:       []entity.ID{"blocked"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		userID       entity.ID
+		reservations []*entity.Reservation
+		err          error
+	}{
+		{"no reservations", "user", nil, nil},
+		{"blocked", "blocked", nil, BlockedError{}},
+		{
+			"under active limit",
+			"user",
+			[]*entity.Reservation{{Status: entity.StatusPending}, {Status: entity.StatusRejected}},
+			nil,
+		},
+		{
+			"at active limit",
+			"user",
+			[]*entity.Reservation{{Status: entity.StatusPending}, {Status: entity.StatusConfirmed}},
+			TooManyActiveError{},
+		},
+		{
+			"old cancellations",
+			"user",
+			[]*entity.Reservation{cancelledAt(now.Add(-48 * time.Hour)), cancelledAt(now.Add(-25 * time.Hour))},
+			nil,
+		},
+		{
+			"recent cancellations",
+			"user",
+			[]*entity.Reservation{cancelledAt(now.Add(-time.Hour)), cancelledAt(now.Add(-2 * time.Hour))},
+			TooManyCancellationsError{},
+		},
+		{
+			"cancellations by driver",
+			"user",
+			[]*entity.Reservation{{Status: entity.StatusCancelledByDriver}, {Status: entity.StatusCancelledByDriver}},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		err := p.AllowsBooking(tt.userID, tt.reservations, now)
+		switch tt.err.(type) {
+		case nil:
+			if err != nil {
+				t.Errorf("%s: got error %v, want none", tt.name, err)
+			}
+		case BlockedError:
+			if _, ok := err.(BlockedError); !ok {
+				t.Errorf("%s: got error %v, want a BlockedError", tt.name, err)
+			}
+		case TooManyActiveError:
+			if _, ok := err.(TooManyActiveError); !ok {
+				t.Errorf("%s: got error %v, want a TooManyActiveError", tt.name, err)
+			}
+		case TooManyCancellationsError:
+			if _, ok := err.(TooManyCancellationsError); !ok {
+				t.Errorf("%s: got error %v, want a TooManyCancellationsError", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestAllowsBookingWithoutLimits(t *testing.T) {
+	p, err := NewPolicy(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reservations := []*entity.Reservation{}
+	for i := 0; i < 10; i++ {
+		reservations = append(reservations, &entity.Reservation{Status: entity.StatusConfirmed}, cancelledAt(time.Now()))
+	}
+
+	err = p.AllowsBooking("user", reservations, time.Now())
+	if err != nil {
+		t.Errorf("got error %v, want none", err)
+	}
+}
+
+func TestVerifyNoOverlap(t *testing.T) {
+	p, err := NewPolicy(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+	ride := &Ride{"new", start, start.Add(time.Hour)}
+
+	tests := []struct {
+		name     string
+		booked   *Ride
+		overlaps bool
+	}{
+		{"before", &Ride{"booked", start.Add(-2 * time.Hour), start.Add(-time.Hour)}, false},
+		{"arriving at departure", &Ride{"booked", start.Add(-time.Hour), start}, false},
+		{"departing at arrival", &Ride{"booked", start.Add(time.Hour), start.Add(2 * time.Hour)}, false},
+		{"during", &Ride{"booked", start.Add(15 * time.Minute), start.Add(30 * time.Minute)}, true},
+		{"across departure", &Ride{"booked", start.Add(-time.Hour), start.Add(time.Minute)}, true},
+	}
+
+	for _, tt := range tests {
+		err := p.VerifyNoOverlap(ride, []*Ride{tt.booked})
+		if _, ok := err.(OverlapError); ok != tt.overlaps {
+			t.Errorf("%s: got error %v, want overlap %t", tt.name, err, tt.overlaps)
+		}
+	}
+
+	p, err = NewPolicy(&Config{AllowOverlaps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.VerifyNoOverlap(ride, []*Ride{{"booked", start, start.Add(time.Hour)}})
+	if err != nil {
+		t.Errorf("got error %v when overlaps are allowed", err)
+	}
+}
@@ -0,0 +1,124 @@
+package cancellation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Config contains the information required to configure a cancellation
+// policy.
+type Config struct {
+	// FreePeriod specifies how long before the departure a reservation can
+	// be cancelled free of charge.
+	FreePeriod time.Duration
+
+	// LateFee specifies the fee, as a percentage of the fare, charged when a
+	// reservation is cancelled after the free period but before departure.
+	LateFee int
+
+	// NoShowFee specifies the fee, as a percentage of the fare, charged when
+	// a reservation is cancelled after departure.
+	NoShowFee int
+}
+
+const (
+	// DefaultFreePeriod represents the default amount of time before
+	// departure during which a reservation can be cancelled free of charge.
+	DefaultFreePeriod = 24 * time.Hour
+
+	// DefaultLateFee represents the default fee charged for a late
+	// cancellation.
+	DefaultLateFee = 50
+
+	// DefaultNoShowFee represents the default fee charged for a cancellation
+	// after departure.
+	DefaultNoShowFee = 100
+)
+
+const (
+	// RuleFree is the name of the rule applied when a reservation is
+	// cancelled before the free period ends.
+	RuleFree = "free"
+
+	// RuleLate is the name of the rule applied when a reservation is
+	// cancelled after the free period, but before departure.
+	RuleLate = "late"
+
+	// RuleNoShow is the name of the rule applied when a reservation is
+	// cancelled after departure.
+	RuleNoShow = "no-show"
+
+	// RuleRejected is the name of the rule applied when a reservation is
+	// rejected by the driver. No fee is charged.
+	RuleRejected = "rejected"
+
+	// RuleDriver is the name of the rule applied when a reservation is
+	// cancelled by the driver. No fee is charged.
+	RuleDriver = "driver"
+
+	// RuleTripCancelled is the name of the rule applied when a reservation
+	// is cancelled because its trip was cancelled. No fee is charged.
+	RuleTripCancelled = "trip-cancelled"
+)
+
+// Validate looks at the configuration's contents to ensure it has all the
+// required fields.
+func (conf *Config) validate() error {
+	if conf.FreePeriod < 0 {
+		return errors.New("free period must be positive")
+	}
+
+	if conf.LateFee < 0 || conf.LateFee > 100 {
+		return errors.New("late fee must be between 0 and 100")
+	}
+
+	if conf.NoShowFee < 0 || conf.NoShowFee > 100 {
+		return errors.New("no-show fee must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// A Policy determines the consequences of cancelling a reservation based on
+// how long before departure it is cancelled.
+type Policy struct {
+	conf *Config
+}
+
+// NewPolicy creates a cancellation policy with the given configuration.
+func NewPolicy(conf *Config) (*Policy, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("cancellation: missing configuration")
+	}
+
+	err := conf.validate()
+	if err != nil {
+		return nil, fmt.Errorf("cancellation: configuration %s", err)
+	}
+
+	return &Policy{conf}, nil
+}
+
+// Evaluate determines which rule applies to a cancellation made at the given
+// time for a departure at the given time, and returns the resulting
+// cancellation.
+func (p *Policy) Evaluate(departure time.Time, now time.Time) *entity.Cancellation {
+	c := &entity.Cancellation{CancelledAt: now}
+
+	switch {
+	case !now.Before(departure):
+		c.Rule = RuleNoShow
+		c.Fee = p.conf.NoShowFee
+	case now.After(departure.Add(-p.conf.FreePeriod)):
+		c.Rule = RuleLate
+		c.Fee = p.conf.LateFee
+	default:
+		c.Rule = RuleFree
+		c.Fee = 0
+	}
+
+	return c
+}
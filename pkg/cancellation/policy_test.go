@@ -0,0 +1,57 @@
+package cancellation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewPolicyValidatesConfiguration(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *Config
+	}{
+		{"missing", nil},
+		{"negative free period", &Config{FreePeriod: -time.Hour}},
+		{"negative late fee", &Config{LateFee: -1}},
+		{"late fee over 100", &Config{LateFee: 101}},
+		{"no-show fee over 100", &Config{NoShowFee: 101}},
+	}
+
+	for _, tt := range tests {
+		_, err := NewPolicy(tt.conf)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := NewPolicy(&Config{FreePeriod: 24 * time.Hour, LateFee: 50, NoShowFee: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	departure := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		now  time.Time
+		rule string
+		fee  int
+	}{
+		{"before free period ends", departure.Add(-48 * time.Hour), RuleFree, 0},
+		{"as free period ends", departure.Add(-24 * time.Hour), RuleFree, 0},
+		{"after free period", departure.Add(-time.Hour), RuleLate, 50},
+		{"at departure", departure, RuleNoShow, 100},
+		{"after departure", departure.Add(time.Hour), RuleNoShow, 100},
+	}
+
+	for _, tt := range tests {
+		c := p.Evaluate(departure, tt.now)
+		if c.Rule != tt.rule || c.Fee != tt.fee {
+			t.Errorf("%s: got rule %q with fee %d, want rule %q with fee %d", tt.name, c.Rule, c.Fee, tt.rule, tt.fee)
+		}
+		if !c.CancelledAt.Equal(tt.now) {
+			t.Errorf("%s: got cancellation time %s, want %s", tt.name, c.CancelledAt, tt.now)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package checkin
+
+// A NotAllowedError is an error that represents that a passenger cannot check
+// in, for example because they are too far from their stop or it is too early.
+type NotAllowedError struct {
+	msg string
+}
+
+func (e NotAllowedError) Error() string {
+	return e.msg
+}
@@ -0,0 +1,116 @@
+package checkin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Config contains the information required to configure a check-in policy.
+type Config struct {
+	// Radius specifies how close, in meters, a passenger must be to the stop
+	// they are picked up at to check in by themselves.
+	Radius float64
+
+	// OpensBefore specifies how long before the departure from their stop a
+	// passenger can check in by themselves.
+	OpensBefore time.Duration
+
+	// GracePeriod specifies how long after the departure from their stop a
+	// passenger can still check in before being marked as a no-show.
+	GracePeriod time.Duration
+}
+
+const (
+	// DefaultRadius represents the default distance, in meters, from the stop
+	// within which a passenger can check in by themselves.
+	DefaultRadius = 200
+
+	// DefaultOpensBefore represents the default amount of time before
+	// departure from which a passenger can check in by themselves.
+	DefaultOpensBefore = 30 * time.Minute
+
+	// DefaultGracePeriod represents the default amount of time after
+	// departure during which a passenger can still check in.
+	DefaultGracePeriod = 15 * time.Minute
+)
+
+// Validate looks at the configuration's contents to ensure it has all the
+// required fields.
+func (conf *Config) validate() error {
+	if conf.Radius <= 0 {
+		return errors.New("radius must be positive")
+	}
+
+	if conf.OpensBefore < 0 {
+		return errors.New("opening period must be positive")
+	}
+
+	if conf.GracePeriod < 0 {
+		return errors.New("grace period must be positive")
+	}
+
+	return nil
+}
+
+// A Policy determines when and where passengers can check in, and when they
+// are considered no-shows.
+type Policy struct {
+	conf *Config
+}
+
+// NewPolicy creates a check-in policy with the given configuration.
+func NewPolicy(conf *Config) (*Policy, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("checkin: missing configuration")
+	}
+
+	err := conf.validate()
+	if err != nil {
+		return nil, fmt.Errorf("checkin: configuration %s", err)
+	}
+
+	return &Policy{conf}, nil
+}
+
+// AllowsSelfCheckIn ensures that a passenger at the given point can check in
+// by themselves at the given time, for a departure from the given stop at the
+// given time.
+func (p *Policy) AllowsSelfCheckIn(stop *entity.Stop, departure time.Time, point *entity.Point, now time.Time) error {
+	if now.Before(departure.Add(-p.conf.OpensBefore)) {
+		return NotAllowedError{fmt.Sprintf("checkin.Policy: check-in opens %s before departure", p.conf.OpensBefore)}
+	}
+
+	if p.IsNoShow(departure, now) {
+		return NotAllowedError{"checkin.Policy: check-in is closed"}
+	}
+
+	if stop == nil || stop.Point == nil {
+		return NotAllowedError{"checkin.Policy: stop has no location"}
+	}
+
+	if stop.Point.DistanceTo(point) > p.conf.Radius {
+		return NotAllowedError{fmt.Sprintf("checkin.Policy: passenger must be within %.0f meters of the stop", p.conf.Radius)}
+	}
+
+	return nil
+}
+
+// VerifyCode ensures that the code scanned by the driver is the reservation's
+// check-in code.
+func (p *Policy) VerifyCode(r *entity.Reservation, code string) error {
+	if r.CheckInCode == "" || subtle.ConstantTimeCompare([]byte(r.CheckInCode), []byte(code)) != 1 {
+		return NotAllowedError{fmt.Sprintf("checkin.Policy: code does not match the check-in code of reservation \"%s\"", r.ID)}
+	}
+
+	return nil
+}
+
+// IsNoShow returns whether or not a passenger who has not checked in by the
+// given time is a no-show, for a departure at the given time.
+func (p *Policy) IsNoShow(departure time.Time, now time.Time) bool {
+	return now.After(departure.Add(p.conf.GracePeriod))
+}
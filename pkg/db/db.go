@@ -61,12 +61,26 @@ func (conf *Config) validate() error {
 // DB represents a database. It contains a client used to connect to a database
 // server and the database's collections.
 type DB struct {
-	client       *mongo.Client
-	Reservations *mongo.Collection
+	client            *mongo.Client
+	migrations        *mongo.Collection
+	locks             *mongo.Collection
+	Reservations      *mongo.Collection
+	Holds             *mongo.Collection
+	AuditLog          *mongo.Collection
+	Webhooks          *mongo.Collection
+	WebhookDeliveries *mongo.Collection
+	RateLimits        *mongo.Collection
 }
 
 const (
-	reservationCollectionName = "reservations"
+	migrationCollectionName       = "migrations"
+	lockCollectionName            = "locks"
+	reservationCollectionName     = "reservations"
+	holdCollectionName            = "holds"
+	auditLogCollectionName        = "auditLog"
+	webhookCollectionName         = "webhooks"
+	webhookDeliveryCollectionName = "webhookDeliveries"
+	rateLimitCollectionName       = "rateLimits"
 )
 
 // New creates a database by establishing a connection to the database server
@@ -105,10 +119,67 @@ func New(conf *Config) (*DB, error) {
 		return nil, fmt.Errorf("db: no database found with name \"%s\"", conf.Name)
 	}
 
+	migrations := db.Collection(migrationCollectionName)
+	if migrations == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", migrationCollectionName)
+	}
+
+	locks := db.Collection(lockCollectionName)
+	if locks == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", lockCollectionName)
+	}
+
 	reservations := db.Collection(reservationCollectionName)
 	if reservations == nil {
 		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", reservationCollectionName)
 	}
 
-	return &DB{client, reservations}, nil
+	holds := db.Collection(holdCollectionName)
+	if holds == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", holdCollectionName)
+	}
+
+	auditLog := db.Collection(auditLogCollectionName)
+	if auditLog == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", auditLogCollectionName)
+	}
+
+	webhooks := db.Collection(webhookCollectionName)
+	if webhooks == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", webhookCollectionName)
+	}
+
+	webhookDeliveries := db.Collection(webhookDeliveryCollectionName)
+	if webhookDeliveries == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", webhookDeliveryCollectionName)
+	}
+
+	rateLimits := db.Collection(rateLimitCollectionName)
+	if rateLimits == nil {
+		return nil, fmt.Errorf("db: no collection found with name \"%s\" in database", rateLimitCollectionName)
+	}
+
+	return &DB{client, migrations, locks, reservations, holds, auditLog, webhooks, webhookDeliveries, rateLimits}, nil
+}
+
+// duplicateKeyErrorCode represents the code of the error returned by MongoDB
+// when a write violates a unique index.
+const duplicateKeyErrorCode = 11000
+
+// IsDuplicateKeyError returns whether or not the error was returned by MongoDB
+// because a write violates a unique index, such as when a document with the
+// same ID already exists.
+func IsDuplicateKeyError(err error) bool {
+	e, ok := err.(mongo.WriteException)
+	if !ok {
+		return false
+	}
+
+	for _, we := range e.WriteErrors {
+		if we.Code == duplicateKeyErrorCode {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mongodb/mongo-go-driver/bson"
+)
+
+const (
+	// MigrationLockTTL represents how long the migration lock is held before
+	// it expires, in case the instance holding it stops without releasing it.
+	MigrationLockTTL = 10 * time.Minute
+
+	// MigrationLockPollInterval represents how often an instance waiting for
+	// the migration lock tries to take it again.
+	MigrationLockPollInterval = time.Second
+)
+
+// migrationLockID is the ID of the document that locks the migrations.
+const migrationLockID = "migrations"
+
+type lockDocument struct {
+	ID        string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// lockMigrations takes the migration lock, so that the instances of the service
+// that start together do not apply the same migrations at once. It waits for
+// the instance holding the lock to release it, or for the lock to expire, and
+// returns a function that releases it.
+func (db *DB) lockMigrations(ctx context.Context) (func(), error) {
+	owner := uuid.New().String()
+	for {
+		_, err := db.locks.InsertOne(ctx, lockDocument{
+			ID:        migrationLockID,
+			Owner:     owner,
+			ExpiresAt: time.Now().Add(MigrationLockTTL).UTC(),
+		})
+		if err == nil {
+			return func() {
+				_, err := db.locks.DeleteOne(context.Background(), bson.D{
+					{Key: "_id", Value: migrationLockID},
+					{Key: "owner", Value: owner},
+				})
+				if err != nil {
+					log.Printf("db: failed to release migration lock, it expires in %s (%s)", MigrationLockTTL, err)
+				}
+			}, nil
+		} else if !IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("db: failed to take migration lock (%s)", err)
+		}
+
+		// The lock of an instance that stopped without releasing it is
+		// removed once it expires, so that the migrations can go on.
+		_, err = db.locks.DeleteOne(ctx, bson.D{
+			{Key: "_id", Value: migrationLockID},
+			{Key: "expiresAt", Value: bson.D{{Key: "$lte", Value: time.Now().UTC()}}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("db: failed to remove expired migration lock (%s)", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("db: gave up waiting for migration lock (%s)", ctx.Err())
+		case <-time.After(MigrationLockPollInterval):
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/options"
+)
+
+// A Migration is a versioned change to the database's schema, such as the
+// creation of an index or the backfilling of a field.
+//
+// Migrations are applied in order of version, and each of them is applied only
+// once: applied migrations are recorded in the database.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *DB) error
+}
+
+type migrationDocument struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+// DeliveryRetention represents how long webhook deliveries are kept before
+// they expire.
+const DeliveryRetention = 30 * 24 * time.Hour
+
+// Migrations enumerates the database's migrations. New migrations must be
+// appended with a greater version, and existing ones must never be modified.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create indexes on reservations by trip and by user",
+		Up: func(ctx context.Context, db *DB) error {
+			_, err := db.Reservations.Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "tripId", Value: 1}, {Key: "userId", Value: 1}},
+					Options: options.Index().SetName("tripId_userId"),
+				},
+				{
+					Keys:    bson.D{{Key: "userId", Value: 1}},
+					Options: options.Index().SetName("userId"),
+				},
+			})
+			return err
+		},
+	},
+	{
+		Version:     2,
+		Description: "flag active reservations and prevent a user from booking the same trip twice",
+		Up: func(ctx context.Context, db *DB) error {
+			_, err := db.Reservations.UpdateMany(ctx,
+				bson.D{{Key: "status", Value: bson.D{{Key: "$ne", Value: "cancelled"}}}},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "active", Value: true}}}},
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = db.Reservations.UpdateMany(ctx,
+				bson.D{{Key: "status", Value: "cancelled"}},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "active", Value: false}}}},
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = db.Reservations.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{{Key: "tripId", Value: 1}, {Key: "userId", Value: 1}},
+				Options: options.Index().
+					SetName("tripId_userId_active_unique").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.D{{Key: "active", Value: true}}),
+			})
+			return err
+		},
+	},
+	{
+		Version:     3,
+		Description: "create indexes on webhooks and webhook deliveries, and expire old deliveries",
+		Up: func(ctx context.Context, db *DB) error {
+			_, err := db.Webhooks.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "eventTypes", Value: 1}},
+				Options: options.Index().SetName("eventTypes"),
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = db.WebhookDeliveries.Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+					Options: options.Index().SetName("status_nextAttemptAt"),
+				},
+				{
+					Keys:    bson.D{{Key: "webhookId", Value: 1}, {Key: "createdAt", Value: -1}},
+					Options: options.Index().SetName("webhookId_createdAt"),
+				},
+				{
+					Keys: bson.D{{Key: "createdAt", Value: 1}},
+					Options: options.Index().
+						SetName("createdAt_ttl").
+						SetExpireAfterSeconds(int32(DeliveryRetention / time.Second)),
+				},
+			})
+			return err
+		},
+	},
+	{
+		Version:     4,
+		Description: "initialize the version of reservations",
+		Up: func(ctx context.Context, db *DB) error {
+			_, err := db.Reservations.UpdateMany(ctx,
+				bson.D{{Key: "version", Value: bson.D{{Key: "$exists", Value: false}}}},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "version", Value: 1}}}},
+			)
+			return err
+		},
+	},
+	{
+		Version:     5,
+		Description: "create an index on holds by expiration",
+		Up: func(ctx context.Context, db *DB) error {
+			_, err := db.Holds.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+				Options: options.Index().SetName("expiresAt"),
+			})
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "create an index on audit log entries by reservation",
+		Up: func(ctx context.Context, db *DB) error {
+			_, err := db.AuditLog.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "reservationId", Value: 1}, {Key: "timestamp", Value: 1}},
+				Options: options.Index().SetName("reservationId_timestamp"),
+			})
+			return err
+		},
+	},
+	{
+		Version:     7,
+		Description: "expire rate limit buckets once they are full again",
+		Up: func(ctx context.Context, db *DB) error {
+			_, err := db.RateLimits.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{{Key: "expiresAt", Value: 1}},
+				Options: options.Index().
+					SetName("expiresAt_ttl").
+					SetExpireAfterSeconds(0),
+			})
+			return err
+		},
+	},
+}
+
+// Migrate applies the migrations that were not applied yet, in order of
+// version, and returns the versions that were applied.
+//
+// It stops at the first migration that fails, leaving the following ones
+// unapplied. Only one instance of the service applies migrations at a time:
+// the others wait for it to be done, and then find them applied.
+func (db *DB) Migrate(ctx context.Context) ([]int, error) {
+	unlock, err := db.lockMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, len(Migrations))
+	copy(migrations, Migrations)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	versions := []int{}
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		err = m.Up(ctx, db)
+		if err != nil {
+			return versions, fmt.Errorf("db: failed to apply migration %d \"%s\" (%s)", m.Version, m.Description, err)
+		}
+
+		_, err = db.migrations.InsertOne(ctx, migrationDocument{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			return versions, fmt.Errorf("db: failed to record migration %d (%s)", m.Version, err)
+		}
+
+		versions = append(versions, m.Version)
+	}
+
+	return versions, nil
+}
+
+func (db *DB) appliedMigrations(ctx context.Context) (map[int]bool, error) {
+	cur, err := db.migrations.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to find applied migrations (%s)", err)
+	}
+	defer cur.Close(ctx)
+
+	applied := make(map[int]bool)
+	for cur.Next(ctx) {
+		var d migrationDocument
+		err := cur.Decode(&d)
+		if err != nil {
+			return nil, fmt.Errorf("db: failed to decode applied migration (%s)", err)
+		}
+
+		applied[d.Version] = true
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("db: failed to find applied migrations (%s)", err)
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// AuditEntry contains the record of an operation performed on a reservation:
+// who performed it, as part of which request, and what changed.
+type AuditEntry struct {
+	ID            ID        `json:"id"`
+	ReservationID ID        `json:"reservationId"`
+	Operation     string    `json:"operation"`
+	Actor         *Actor    `json:"actor"`
+	RequestID     string    `json:"requestId,omitempty"`
+	Changes       []*Change `json:"changes"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Actor contains the information of who performed an operation.
+type Actor struct {
+	Kind  ActorKind `json:"kind"`
+	SubID string    `json:"subId,omitempty"`
+	Email string    `json:"email,omitempty"`
+}
+
+// An ActorKind represents what kind of actor performed an operation.
+type ActorKind string
+
+const (
+	// ActorUser represents an authenticated user.
+	ActorUser = ActorKind("user")
+
+	// ActorService represents another service, authenticated with basic auth.
+	ActorService = ActorKind("service")
+
+	// ActorSystem represents the service itself, such as a background job.
+	ActorSystem = ActorKind("system")
+)
+
+// Change contains the value of a field before and after an operation.
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
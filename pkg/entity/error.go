@@ -1,12 +1,51 @@
 package entity
 
+import (
+	"fmt"
+	"strings"
+)
+
 // A ValidationError is an error that occurs when validating an entity fails.
 // This can happen when, for example, an entity is missing a required field a
 // value is incorrect or out of bounds.
 type ValidationError struct {
-	msg string
+	msg    string
+	params []*InvalidParam
 }
 
 func (e ValidationError) Error() string {
 	return e.msg
 }
+
+// InvalidParams returns the fields that are invalid, if the error is about
+// specific fields.
+func (e ValidationError) InvalidParams() []*InvalidParam {
+	return e.params
+}
+
+// An InvalidParam describes why the value of a field is invalid.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// NewValidationError creates a validation error with the given message.
+func NewValidationError(msg string) ValidationError {
+	return ValidationError{msg: msg}
+}
+
+// NewFieldValidationError creates a validation error about the field with the
+// given name, for the given reason (ex. "is missing").
+func NewFieldValidationError(name string, reason string) ValidationError {
+	return NewInvalidParamsError([]*InvalidParam{{name, reason}})
+}
+
+// NewInvalidParamsError creates a validation error about several fields.
+func NewInvalidParamsError(params []*InvalidParam) ValidationError {
+	msgs := make([]string, len(params))
+	for i, p := range params {
+		msgs[i] = fmt.Sprintf("%s %s", p.Name, p.Reason)
+	}
+
+	return ValidationError{strings.Join(msgs, "; "), params}
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// Hold contains the information of seats that are held on a trip for a short
+// period of time, until they are converted to a reservation or expire.
+type Hold struct {
+	ID            ID        `json:"id"`
+	TripID        ID        `json:"tripId"`
+	UserID        ID        `json:"userId"`
+	SourceID      ID        `json:"sourceId"`
+	DestinationID ID        `json:"destinationId"`
+	Seats         int       `json:"seats"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Validate validates that the hold's required fields are filled out correctly.
+func (h *Hold) Validate() error {
+	return h.Reservation().Validate()
+}
+
+// Reservation returns the reservation represented by the hold. It has the
+// hold's ID, so that the seats held on the trip can be handed over to the
+// reservation the hold is converted to.
+func (h *Hold) Reservation() *Reservation {
+	return &Reservation{
+		ID:            h.ID,
+		TripID:        h.TripID,
+		UserID:        h.UserID,
+		SourceID:      h.SourceID,
+		DestinationID: h.DestinationID,
+		Seats:         h.Seats,
+	}
+}
+
+// Matches returns whether or not the hold covers the same seats as the given
+// reservation.
+func (h *Hold) Matches(r *Reservation) bool {
+	return h.TripID == r.TripID &&
+		h.UserID == r.UserID &&
+		h.SourceID == r.SourceID &&
+		h.DestinationID == r.DestinationID &&
+		h.Seats == r.Seats
+}
+
+// IsExpired returns whether or not the hold expired at the given time.
+func (h *Hold) IsExpired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
@@ -2,18 +2,138 @@ package entity
 
 import (
 	"fmt"
+	"time"
 )
 
 // Reservation contains a reservation's information.
 type Reservation struct {
-	ID            ID  `json:"id"`
-	TripID        ID  `json:"tripId"`
-	UserID        ID  `json:"userId"`
-	SourceID      ID  `json:"sourceId"`
-	DestinationID ID  `json:"destinationId"`
-	Seats         int `json:"seats"`
+	ID            ID            `json:"id"`
+	TripID        ID            `json:"tripId"`
+	UserID        ID            `json:"userId"`
+	SourceID      ID            `json:"sourceId"`
+	DestinationID ID            `json:"destinationId"`
+	Seats         int           `json:"seats"`
+	Fare          *Fare         `json:"fare,omitempty"`
+	Payment       *Payment      `json:"payment,omitempty"`
+	Status        Status        `json:"status,omitempty"`
+	Cancellation  *Cancellation `json:"cancellation,omitempty"`
+	CheckIn       *CheckIn      `json:"checkIn,omitempty"`
+	Version       int           `json:"version"`
+
+	// CheckInCode is the code the passenger shows the driver to check in. It
+	// is never encoded along with the reservation, so that it is only given
+	// to the passenger, in their boarding pass.
+	CheckInCode string `json:"-"`
+
+	// HoldID is the ID of the hold consumed to book the reservation, if any.
+	HoldID ID `json:"holdId,omitempty"`
+}
+
+// InitialVersion represents the version of a reservation when it is created.
+// The version is incremented every time the reservation is modified.
+const InitialVersion = 1
+
+// A Status represents the state of a reservation.
+type Status string
+
+const (
+	// StatusPending represents a reservation that holds seats on a trip, but
+	// that has yet to be accepted by the driver.
+	StatusPending = Status("pending")
+
+	// StatusConfirmed represents a reservation that holds seats on a trip.
+	StatusConfirmed = Status("confirmed")
+
+	// StatusRejected represents a reservation that was rejected by the driver
+	// and no longer holds seats on a trip.
+	StatusRejected = Status("rejected")
+
+	// StatusCancelled represents a reservation that was cancelled and no
+	// longer holds seats on a trip.
+	StatusCancelled = Status("cancelled")
+
+	// StatusCancelledByDriver represents a reservation that was cancelled by
+	// the driver and no longer holds seats on a trip.
+	StatusCancelledByDriver = Status("cancelled-by-driver")
+
+	// StatusCompleted represents a confirmed reservation whose trip was
+	// completed.
+	StatusCompleted = Status("completed")
+
+	// StatusNoShow represents a confirmed reservation whose passenger did not
+	// check in before the trip left.
+	StatusNoShow = Status("no-show")
+
+	// StatusExpired represents a pending reservation that the driver did not
+	// accept before the trip was completed.
+	StatusExpired = Status("expired")
+)
+
+// Fare contains the price of a reservation. The amount is expressed in the
+// currency's smallest unit (ex. cents).
+type Fare struct {
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// Payment contains the state of the payment of a reservation's fare.
+type Payment struct {
+	AuthorizationID string        `json:"-"`
+	Status          PaymentStatus `json:"status"`
+	Captured        int           `json:"captured"`
+	Refunded        int           `json:"refunded"`
 }
 
+// A PaymentStatus represents the state of a reservation's payment.
+type PaymentStatus string
+
+const (
+	// PaymentAuthorized represents a payment for which the fare is on hold.
+	PaymentAuthorized = PaymentStatus("authorized")
+
+	// PaymentCaptured represents a payment for which the passenger was
+	// charged.
+	PaymentCaptured = PaymentStatus("captured")
+
+	// PaymentVoided represents a payment that was released without charging
+	// the passenger.
+	PaymentVoided = PaymentStatus("voided")
+
+	// PaymentRefunded represents a payment that was captured, and then given
+	// back to the passenger, in part or in full.
+	PaymentRefunded = PaymentStatus("refunded")
+)
+
+// Cancellation contains the details of a reservation's cancellation, such as
+// the cancellation policy rule that was applied and the resulting fee.
+type Cancellation struct {
+	Rule        string    `json:"rule"`
+	Fee         int       `json:"fee"`
+	Reason      string    `json:"reason,omitempty"`
+	CancelledAt time.Time `json:"cancelledAt"`
+}
+
+// CheckIn contains the details of a passenger's check-in, such as how and when
+// they checked in.
+type CheckIn struct {
+	Method      CheckInMethod `json:"method"`
+	Point       *Point        `json:"point,omitempty"`
+	CheckedInAt time.Time     `json:"checkedInAt"`
+}
+
+// A CheckInMethod represents how a passenger checked in.
+type CheckInMethod string
+
+const (
+	// CheckInScan represents a check-in made by the driver scanning the
+	// passenger's check-in code.
+	CheckInScan = CheckInMethod("scan")
+
+	// CheckInSelf represents a check-in made by the passenger near the stop
+	// they are picked up at.
+	CheckInSelf = CheckInMethod("self")
+)
+
 const (
 	// MinimumSeats represents the minimum seats possible in a car.
 	MinimumSeats = 1
@@ -24,25 +144,69 @@ const (
 
 // Validate validates that the reservation's required fields are filled out correctly.
 func (r *Reservation) Validate() error {
-	if r.TripID.IsZero() {
-		return ValidationError{"Trip's ID is missing"}
+	if r.UserID.IsZero() {
+		return NewFieldValidationError("userId", "is missing")
 	}
 
-	if r.UserID.IsZero() {
-		return ValidationError{"User's ID is missing"}
+	return r.ValidateSegment()
+}
+
+// ValidateSegment validates that the fields describing which part of a trip is
+// reserved, and how many seats are reserved, are filled out correctly.
+func (r *Reservation) ValidateSegment() error {
+	if r.TripID.IsZero() {
+		return NewFieldValidationError("tripId", "is missing")
 	}
 
 	if r.SourceID.IsZero() {
-		return ValidationError{"Source's ID is missing"}
+		return NewFieldValidationError("sourceId", "is missing")
 	}
 
 	if r.DestinationID.IsZero() {
-		return ValidationError{"Destination's ID is missing"}
+		return NewFieldValidationError("destinationId", "is missing")
 	}
 
 	if r.Seats < MinimumSeats || r.Seats > MaximumSeats {
-		return ValidationError{fmt.Sprintf("number of seats must be between %d and %d", MinimumSeats, MaximumSeats)}
+		return NewFieldValidationError("seats", fmt.Sprintf("must be between %d and %d", MinimumSeats, MaximumSeats))
 	}
 
 	return nil
 }
+
+// IsCancelled returns whether or not the reservation was cancelled, either by
+// the passenger or by the driver.
+func (r *Reservation) IsCancelled() bool {
+	return r.Status == StatusCancelled || r.Status == StatusCancelledByDriver
+}
+
+// IsActive returns whether or not the reservation holds seats on its trip.
+func (r *Reservation) IsActive() bool {
+	return r.Status == StatusPending || r.Status == StatusConfirmed
+}
+
+// Clone returns a deep copy of the reservation.
+func (r *Reservation) Clone() *Reservation {
+	c := *r
+
+	if r.Fare != nil {
+		fare := *r.Fare
+		c.Fare = &fare
+	}
+
+	if r.Payment != nil {
+		payment := *r.Payment
+		c.Payment = &payment
+	}
+
+	if r.Cancellation != nil {
+		cancellation := *r.Cancellation
+		c.Cancellation = &cancellation
+	}
+
+	if r.CheckIn != nil {
+		checkIn := *r.CheckIn
+		c.CheckIn = &checkIn
+	}
+
+	return &c
+}
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// Trip contains the information of a trip, as exposed by the trip-service,
+// that is needed to handle reservations.
+type Trip struct {
+	ID       ID        `json:"id"`
+	DriverID ID        `json:"driverId"`
+	LeaveAt  time.Time `json:"leaveAt"`
+	ArriveBy time.Time `json:"arriveBy"`
+	Seats    int       `json:"seats"`
+	Stops    []*Stop   `json:"stops"`
+
+	// PricePerSeat is the price, in cents, of a seat for the whole trip.
+	PricePerSeat int    `json:"pricePerSeat"`
+	Currency     string `json:"currency,omitempty"`
+}
+
+// Stop contains a trip's stop information.
+type Stop struct {
+	ID        ID        `json:"id"`
+	Point     *Point    `json:"point,omitempty"`
+	Seats     int       `json:"seats"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Point contains a geographical location.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// earthRadius represents the mean radius of the Earth in meters.
+const earthRadius = 6371000
+
+// DistanceTo computes the great-circle distance, in meters, between the point
+// and another one.
+func (p *Point) DistanceTo(q *Point) float64 {
+	lat1 := p.Latitude * math.Pi / 180
+	lat2 := q.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (q.Longitude - p.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// FindStop retrieves the stop with the given ID on the trip, if it exists.
+func (t *Trip) FindStop(ID ID) *Stop {
+	i := t.StopIndex(ID)
+	if i < 0 {
+		return nil
+	}
+
+	return t.Stops[i]
+}
+
+// StopIndex returns the position of the stop with the given ID in the trip's
+// stops, or -1 if it cannot be found.
+func (t *Trip) StopIndex(ID ID) int {
+	for i, s := range t.Stops {
+		if s != nil && s.ID == ID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// DepartureFrom returns the time at which the trip leaves the stop with the
+// given ID. If the stop cannot be found, or has no timestamp, the trip's
+// departure time is returned instead.
+func (t *Trip) DepartureFrom(stopID ID) time.Time {
+	s := t.FindStop(stopID)
+	if s == nil || s.Timestamp.IsZero() {
+		return t.LeaveAt
+	}
+
+	return s.Timestamp
+}
+
+// ArrivalAt returns the time at which the trip reaches the stop with the given
+// ID. If the stop cannot be found, or has no timestamp, the trip's arrival time
+// is returned instead.
+func (t *Trip) ArrivalAt(stopID ID) time.Time {
+	s := t.FindStop(stopID)
+	if s == nil || s.Timestamp.IsZero() {
+		return t.ArriveBy
+	}
+
+	return s.Timestamp
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Webhook contains a partner's subscription to reservation events, which are
+// pushed to its URL.
+type Webhook struct {
+	ID         ID        `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// MinimumSecretLength represents the minimum length of the secret used to sign
+// a webhook's deliveries.
+const MinimumSecretLength = 16
+
+// Validate validates that the webhook's required fields are filled out
+// correctly.
+func (w *Webhook) Validate() error {
+	if w.URL == "" {
+		return NewFieldValidationError("url", "is missing")
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") {
+		return NewFieldValidationError("url", "must be an absolute HTTP or HTTPS URL")
+	}
+
+	if len(w.EventTypes) == 0 {
+		return NewFieldValidationError("eventTypes", "must contain at least one event type")
+	}
+
+	if len(w.Secret) < MinimumSecretLength {
+		return NewFieldValidationError("secret", fmt.Sprintf("must be at least %d characters long", MinimumSecretLength))
+	}
+
+	return nil
+}
+
+// Subscribes returns whether or not the webhook subscribed to events of the
+// given type.
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery contains the state of the delivery of an event to a webhook.
+type Delivery struct {
+	ID            ID                 `json:"id"`
+	WebhookID     ID                 `json:"webhookId"`
+	EventID       string             `json:"eventId"`
+	EventType     string             `json:"eventType"`
+	Payload       []byte             `json:"-"`
+	Status        DeliveryStatus     `json:"status"`
+	Attempts      []*DeliveryAttempt `json:"attempts"`
+	NextAttemptAt time.Time          `json:"nextAttemptAt,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt"`
+}
+
+// A DeliveryStatus represents the state of a delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending represents a delivery that has yet to succeed and will
+	// be attempted again.
+	DeliveryPending = DeliveryStatus("pending")
+
+	// DeliveryDelivered represents a delivery that was acknowledged by the
+	// webhook.
+	DeliveryDelivered = DeliveryStatus("delivered")
+
+	// DeliveryDead represents a delivery that failed too many times and will
+	// not be attempted again.
+	DeliveryDead = DeliveryStatus("dead")
+
+	// DeliveryReplayed represents a dead delivery that was queued again, as
+	// a new delivery, by an operator.
+	DeliveryReplayed = DeliveryStatus("replayed")
+)
+
+// DeliveryAttempt contains the outcome of an attempt to deliver an event to a
+// webhook.
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
@@ -0,0 +1,96 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+	"github.com/google/uuid"
+)
+
+const (
+	// SpecVersion represents the version of the CloudEvents specification
+	// that events follow.
+	SpecVersion = "1.0"
+
+	// Source represents the context in which events are produced.
+	Source = "/ecovo/reservation-service"
+)
+
+// A Type identifies what happened in the reservation domain.
+type Type string
+
+const (
+	// ReservationCreated is the type of the event emitted when a reservation
+	// is booked.
+	ReservationCreated = Type("ecovo.reservation.created")
+
+	// ReservationModified is the type of the event emitted when a
+	// reservation's information changes.
+	ReservationModified = Type("ecovo.reservation.modified")
+
+	// ReservationCancelled is the type of the event emitted when a
+	// reservation is cancelled.
+	ReservationCancelled = Type("ecovo.reservation.cancelled")
+)
+
+// An Event is a domain event formatted according to the CloudEvents
+// specification (structured JSON mode).
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            Type            `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data,omitempty"`
+
+	// RequestID is an extension attribute carrying the ID of the request that
+	// caused the event, to correlate it with the service's logs.
+	RequestID string `json:"requestid,omitempty"`
+}
+
+// New creates an event of the given type about the given subject, with the
+// given data encoded in JSON. The request ID is taken from the context, if it
+// is present.
+func New(ctx context.Context, t Type, subject string, data interface{}) (*Event, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID, _ := requestid.FromContext(ctx)
+
+	return &Event{
+		SpecVersion:     SpecVersion,
+		ID:              uuid.New().String(),
+		Source:          Source,
+		Type:            t,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            b,
+		RequestID:       requestID,
+	}, nil
+}
+
+// Types enumerates the types of events emitted by the service.
+var Types = []Type{
+	ReservationCreated,
+	ReservationModified,
+	ReservationCancelled,
+}
+
+// IsValid returns whether or not the type is one of the types of events
+// emitted by the service.
+func (t Type) IsValid() bool {
+	for _, v := range Types {
+		if t == v {
+			return true
+		}
+	}
+
+	return false
+}
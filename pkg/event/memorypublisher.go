@@ -0,0 +1,43 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A MemoryPublisher is a publisher that keeps the published events in memory.
+// It is meant to be used in tests.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+// NewMemoryPublisher creates an in-memory publisher.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish stores the event.
+func (p *MemoryPublisher) Publish(e *Event) error {
+	if e == nil {
+		return fmt.Errorf("event.MemoryPublisher: event is nil")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, e)
+
+	return nil
+}
+
+// Events returns the events that were published, in order.
+func (p *MemoryPublisher) Events() []*Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := make([]*Event, len(p.events))
+	copy(events, p.events)
+
+	return events
+}
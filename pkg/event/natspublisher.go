@@ -0,0 +1,53 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// A NATSPublisher is a publisher that publishes events to a NATS server. Each
+// event is published on a subject named after its type.
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+// NewNATSPublisher creates a publisher connected to the NATS server at the
+// given URL.
+func NewNATSPublisher(url string) (*NATSPublisher, error) {
+	if url == "" {
+		return nil, fmt.Errorf("event.NATSPublisher: url is empty")
+	}
+
+	conn, err := nats.Connect(url, nats.Name("reservation-service"))
+	if err != nil {
+		return nil, fmt.Errorf("event.NATSPublisher: failed to connect to server (%s)", err)
+	}
+
+	return &NATSPublisher{conn}, nil
+}
+
+// Publish encodes the event in JSON and publishes it.
+func (p *NATSPublisher) Publish(e *Event) error {
+	if e == nil {
+		return fmt.Errorf("event.NATSPublisher: event is nil")
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("event.NATSPublisher: failed to encode event (%s)", err)
+	}
+
+	err = p.conn.Publish(string(e.Type), b)
+	if err != nil {
+		return fmt.Errorf("event.NATSPublisher: failed to publish event (%s)", err)
+	}
+
+	return nil
+}
+
+// Close closes the connection to the server.
+func (p *NATSPublisher) Close() {
+	p.conn.Close()
+}
@@ -0,0 +1,34 @@
+package event
+
+// Publisher is an interface representing the ability to publish domain events
+// so that other services can react to them.
+type Publisher interface {
+	Publish(e *Event) error
+}
+
+// A NopPublisher is a publisher that discards every event. It is used when no
+// message broker is configured.
+type NopPublisher struct{}
+
+// Publish discards the event.
+func (p NopPublisher) Publish(e *Event) error {
+	return nil
+}
+
+// A MultiPublisher is a publisher that publishes every event through each of
+// its publishers.
+type MultiPublisher []Publisher
+
+// Publish publishes the event through each publisher, even if some of them
+// fail, and returns the first error that occurred.
+func (p MultiPublisher) Publish(e *Event) error {
+	var firstErr error
+	for _, publisher := range p {
+		err := publisher.Publish(e)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,21 @@
+package hold
+
+// A NotFoundError is an error that represents that no hold was found, or that
+// it expired.
+type NotFoundError struct {
+	msg string
+}
+
+func (e NotFoundError) Error() string {
+	return e.msg
+}
+
+// A ForbiddenError is an error that represents that the authenticated user is
+// not allowed to manage a hold, because they did not place it.
+type ForbiddenError struct {
+	msg string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.msg
+}
@@ -0,0 +1,214 @@
+package hold
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/options"
+)
+
+// A MongoRepository is a repository that performs CRUD operations on holds in
+// a MongoDB collection.
+type MongoRepository struct {
+	collection *mongo.Collection
+}
+
+type document struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	TripID        primitive.ObjectID `bson:"tripId"`
+	UserID        primitive.ObjectID `bson:"userId"`
+	SourceID      primitive.ObjectID `bson:"sourceId"`
+	DestinationID primitive.ObjectID `bson:"destinationId"`
+	Seats         int                `bson:"seats"`
+	ExpiresAt     time.Time          `bson:"expiresAt"`
+	CreatedAt     time.Time          `bson:"createdAt"`
+}
+
+func newDocumentFromEntity(h *entity.Hold) (*document, error) {
+	if h == nil {
+		return nil, fmt.Errorf("hold.MongoRepository: entity is nil")
+	}
+
+	ID, err := getObjectID(h.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tripID, err := getObjectID(h.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := getObjectID(h.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceID, err := getObjectID(h.SourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationID, err := getObjectID(h.DestinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &document{
+		ID:            ID,
+		TripID:        tripID,
+		UserID:        userID,
+		SourceID:      sourceID,
+		DestinationID: destinationID,
+		Seats:         h.Seats,
+		ExpiresAt:     h.ExpiresAt,
+		CreatedAt:     h.CreatedAt,
+	}, nil
+}
+
+func (d document) Entity() *entity.Hold {
+	return &entity.Hold{
+		ID:            entity.NewIDFromHex(d.ID.Hex()),
+		TripID:        entity.NewIDFromHex(d.TripID.Hex()),
+		UserID:        entity.NewIDFromHex(d.UserID.Hex()),
+		SourceID:      entity.NewIDFromHex(d.SourceID.Hex()),
+		DestinationID: entity.NewIDFromHex(d.DestinationID.Hex()),
+		Seats:         d.Seats,
+		ExpiresAt:     d.ExpiresAt,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+// NewMongoRepository creates a hold repository for a MongoDB collection.
+func NewMongoRepository(collection *mongo.Collection) (Repository, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("hold.MongoRepository: collection is nil")
+	}
+
+	return &MongoRepository{collection}, nil
+}
+
+// FindByID retrieves the hold with the given ID, if it exists.
+func (r *MongoRepository) FindByID(ID entity.ID) (*entity.Hold, error) {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("hold.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "_id", Value: objectID}}
+	var d document
+	err = r.collection.FindOne(context.TODO(), filter).Decode(&d)
+	if err != nil {
+		return nil, fmt.Errorf("hold.MongoRepository: no hold found with ID \"%s\" (%s)", ID, err)
+	}
+
+	return d.Entity(), nil
+}
+
+// FindExpired retrieves the holds that expired at the given time, from the
+// oldest to the newest.
+func (r *MongoRepository) FindExpired(now time.Time, limit int) ([]*entity.Hold, error) {
+	filter := bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$lte", Value: now}}}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expiresAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("hold.MongoRepository: failed to find holds (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	holds := []*entity.Hold{}
+	for cur.Next(context.TODO()) {
+		var d document
+		err := cur.Decode(&d)
+		if err != nil {
+			return nil, fmt.Errorf("hold.MongoRepository: failed to decode hold (%s)", err)
+		}
+
+		holds = append(holds, d.Entity())
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("hold.MongoRepository: failed to find holds (%s)", err)
+	}
+
+	return holds, nil
+}
+
+// Create stores the new hold in the database and returns the unique
+// identifier that was generated for it.
+func (r *MongoRepository) Create(h *entity.Hold) (entity.ID, error) {
+	d, err := newDocumentFromEntity(h)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("hold.MongoRepository: failed to create hold document from entity (%s)", err)
+	}
+
+	resp, err := r.collection.InsertOne(context.TODO(), d)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("hold.MongoRepository: failed to create hold (%s)", err)
+	}
+
+	ID, ok := resp.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return entity.NilID, fmt.Errorf("hold.MongoRepository: failed to get ID of created hold")
+	}
+
+	return entity.ID(ID.Hex()), nil
+}
+
+// Delete removes the hold with the given ID from the database.
+func (r *MongoRepository) Delete(ID entity.ID) error {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return fmt.Errorf("hold.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "_id", Value: objectID}}
+	_, err = r.collection.DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("hold.MongoRepository: failed to delete hold with ID \"%s\" (%s)", ID, err)
+	}
+
+	return nil
+}
+
+// DeleteUnexpired atomically removes the hold with the given ID from the
+// database, as long as it did not expire at the given time, and returns it.
+func (r *MongoRepository) DeleteUnexpired(ID entity.ID, now time.Time) (*entity.Hold, error) {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("hold.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objectID},
+		{Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: now}}},
+	}
+	var d document
+	err = r.collection.FindOneAndDelete(context.TODO(), filter).Decode(&d)
+	if err != nil {
+		return nil, fmt.Errorf("hold.MongoRepository: no unexpired hold found with ID \"%s\" (%s)", ID, err)
+	}
+
+	return d.Entity(), nil
+}
+
+// Gets an object ID from an entity of type ID
+func getObjectID(rawID entity.ID) (primitive.ObjectID, error) {
+	if rawID.IsZero() {
+		return primitive.NilObjectID, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(rawID.Hex())
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("hold.MongoRepository: failed to create object")
+	}
+	return objectID, nil
+}
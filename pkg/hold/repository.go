@@ -0,0 +1,20 @@
+package hold
+
+import (
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Repository is an interface representing the ability to perform CRUD
+// operations on holds in a database.
+type Repository interface {
+	FindByID(ID entity.ID) (*entity.Hold, error)
+	FindExpired(now time.Time, limit int) ([]*entity.Hold, error)
+	Create(h *entity.Hold) (entity.ID, error)
+	Delete(ID entity.ID) error
+
+	// DeleteUnexpired removes the hold with the given ID, as long as it did
+	// not expire at the given time, and returns it.
+	DeleteUnexpired(ID entity.ID, now time.Time) (*entity.Hold, error)
+}
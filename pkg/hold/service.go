@@ -0,0 +1,201 @@
+package hold
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+)
+
+// DefaultTTL represents the default amount of time during which seats are
+// held.
+const DefaultTTL = 10 * time.Minute
+
+// UseCase is an interface representing the ability to handle the business
+// logic that involves holds.
+type UseCase interface {
+	Place(ctx context.Context, h *entity.Hold) (*entity.Hold, error)
+	FindByID(ctx context.Context, tripID entity.ID, ID entity.ID) (*entity.Hold, error)
+	Release(ctx context.Context, tripID entity.ID, ID entity.ID) error
+	Consume(ID entity.ID, r *entity.Reservation) (*entity.Hold, error)
+	ReleaseExpired() (int, error)
+}
+
+// A Service handles the business logic related to holds.
+//
+// Held seats are registered on the trip as a reservation that has the hold's
+// ID, so that they are no longer available to other passengers.
+type Service struct {
+	repo        Repository
+	tripService trip.UseCase
+	ttl         time.Duration
+}
+
+// NewService creates a hold service that holds seats on trips through the trip
+// service for the given amount of time.
+func NewService(repo Repository, tripService trip.UseCase, ttl time.Duration) *Service {
+	return &Service{repo, tripService, ttl}
+}
+
+// Place holds seats on a trip until the hold expires, on behalf of the
+// authenticated user. A hold without a user is placed for them, and a hold for
+// another user is refused.
+func (s *Service) Place(ctx context.Context, h *entity.Hold) (*entity.Hold, error) {
+	if h == nil {
+		return nil, fmt.Errorf("hold.Service: hold is nil")
+	}
+
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil || userInfo.SubID == "" {
+		return nil, ForbiddenError{"hold.Service: only an authenticated user can place a hold"}
+	}
+
+	if h.UserID.IsZero() {
+		h.UserID = entity.NewIDFromHex(userInfo.SubID)
+	} else if h.UserID.Hex() != userInfo.SubID {
+		return nil, ForbiddenError{fmt.Sprintf("hold.Service: user \"%s\" cannot place a hold for user \"%s\"", userInfo.SubID, h.UserID)}
+	}
+
+	err = h.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	h.ID = entity.NilID
+	h.CreatedAt = now
+	h.ExpiresAt = now.Add(s.ttl)
+
+	h.ID, err = s.repo.Create(h)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tripService.RegisterReservation(h.Reservation())
+	if err != nil {
+		delErr := s.repo.Delete(h.ID)
+		if delErr != nil {
+			return nil, delErr
+		}
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// FindByID retrieves the hold with the given ID placed on the trip with the
+// given ID, if it exists, as long as the authenticated user placed it.
+func (s *Service) FindByID(ctx context.Context, tripID entity.ID, ID entity.ID) (*entity.Hold, error) {
+	h, err := s.repo.FindByID(ID)
+	if err != nil {
+		return nil, NotFoundError{err.Error()}
+	}
+
+	if h.TripID != tripID {
+		return nil, NotFoundError{fmt.Sprintf("hold.Service: no hold found with ID \"%s\" on trip \"%s\"", ID, tripID)}
+	}
+
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil || userInfo.SubID == "" || userInfo.SubID != h.UserID.Hex() {
+		return nil, ForbiddenError{fmt.Sprintf("hold.Service: only the user who placed hold \"%s\" can manage it", ID)}
+	}
+
+	return h, nil
+}
+
+// Release gives back the seats of the hold with the given ID placed on the
+// trip with the given ID before it expires, as long as the authenticated user
+// placed it.
+func (s *Service) Release(ctx context.Context, tripID entity.ID, ID entity.ID) error {
+	_, err := s.FindByID(ctx, tripID, ID)
+	if err != nil {
+		return err
+	}
+
+	h, err := s.repo.DeleteUnexpired(ID, time.Now().UTC())
+	if err != nil {
+		return NotFoundError{err.Error()}
+	}
+
+	return s.tripService.DeleteReservation(h.Reservation())
+}
+
+// Consume removes the hold with the given ID so that its seats can be handed
+// over to the given reservation, which must cover the same seats. The seats
+// stay registered on the trip under the hold's ID, which the reservation must
+// take.
+func (s *Service) Consume(ID entity.ID, r *entity.Reservation) (*entity.Hold, error) {
+	if r == nil {
+		return nil, fmt.Errorf("hold.Service: reservation is nil")
+	}
+
+	h, err := s.repo.FindByID(ID)
+	if err != nil {
+		return nil, NotFoundError{err.Error()}
+	}
+
+	if !h.Matches(r) {
+		return nil, entity.NewValidationError("reservation does not match the hold's trip, user, stops or seats")
+	}
+
+	h, err = s.repo.DeleteUnexpired(ID, time.Now().UTC())
+	if err != nil {
+		return nil, NotFoundError{err.Error()}
+	}
+
+	return h, nil
+}
+
+// ReleaseExpired gives back the seats of holds that expired and returns how
+// many were released.
+func (s *Service) ReleaseExpired() (int, error) {
+	holds, err := s.repo.FindExpired(time.Now().UTC(), releaseBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, h := range holds {
+		err = s.tripService.DeleteReservation(h.Reservation())
+		if err != nil {
+			log.Printf("hold.Service: failed to release hold \"%s\" on trip \"%s\" (%s)", h.ID, h.TripID, err)
+			continue
+		}
+
+		err = s.repo.Delete(h.ID)
+		if err != nil {
+			return released, err
+		}
+
+		released++
+	}
+
+	return released, nil
+}
+
+// releaseBatchSize represents the maximum number of expired holds released at
+// once.
+const releaseBatchSize = 100
+
+// Sweep releases the expired holds at every interval, until the context is
+// done.
+func Sweep(ctx context.Context, service UseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := service.ReleaseExpired()
+			if err != nil {
+				log.Printf("hold.Sweep: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package identity describes the user on whose behalf a request is made, so
+// that the services can tell who they are acting for.
+package identity
+
+import (
+	"context"
+	"fmt"
+)
+
+// UserInfo contains a user's basic information extracted from an access token.
+type UserInfo struct {
+	SubID     string `json:"sub,omitempty"`
+	FirstName string `json:"given_name"`
+	LastName  string `json:"family_name"`
+	Picture   string `json:"picture"`
+	Email     string `json:"email"`
+}
+
+type contextKey string
+
+func (c contextKey) String() string {
+	return "identity." + string(c)
+}
+
+const (
+	// UserInfoContextKey represents the key used to store and retrieve the
+	// user information from the request context.
+	UserInfoContextKey = contextKey("userInfo")
+)
+
+// FromContext extracts an authenticated user's information from the request's
+// context.
+func FromContext(ctx context.Context) (*UserInfo, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("identity: request context is nil")
+	}
+
+	userInfo, ok := ctx.Value(UserInfoContextKey).(*UserInfo)
+	if !ok {
+		return nil, fmt.Errorf("identity: %s not found in context", UserInfoContextKey)
+	}
+
+	return userInfo, nil
+}
@@ -0,0 +1,31 @@
+package payment
+
+// A DeclinedError is an error that represents that the payment processor
+// refused to authorize a payment.
+type DeclinedError struct {
+	msg string
+}
+
+func (e DeclinedError) Error() string {
+	return e.msg
+}
+
+// A NotFoundError is an error that represents that no authorization was found.
+type NotFoundError struct {
+	msg string
+}
+
+func (e NotFoundError) Error() string {
+	return e.msg
+}
+
+// An InvalidOperationError is an error that represents that an operation
+// cannot be performed on an authorization given its current state, such as
+// capturing an authorization that was voided.
+type InvalidOperationError struct {
+	msg string
+}
+
+func (e InvalidOperationError) Error() string {
+	return e.msg
+}
@@ -0,0 +1,140 @@
+package payment
+
+import (
+	"fmt"
+	"sync"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"github.com/google/uuid"
+)
+
+// A FakeProvider is a provider that keeps authorizations in memory instead of
+// contacting a payment processor. It is meant to be used in tests.
+type FakeProvider struct {
+	mu             sync.Mutex
+	authorizations map[string]*FakeAuthorization
+
+	// Decline makes every authorization fail when it is set.
+	Decline bool
+}
+
+// A FakeAuthorization contains the state of an authorization made with a fake
+// provider.
+type FakeAuthorization struct {
+	Amount   int
+	Currency string
+	Captured int
+	Refunded int
+	Voided   bool
+}
+
+// NewFakeProvider creates a fake payment provider.
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{authorizations: make(map[string]*FakeAuthorization)}
+}
+
+// Authorize records an authorization for the reservation's fare.
+func (p *FakeProvider) Authorize(r *entity.Reservation) (string, error) {
+	if r == nil || r.Fare == nil {
+		return "", fmt.Errorf("payment.FakeProvider: reservation has no fare")
+	}
+
+	if p.Decline {
+		return "", DeclinedError{"payment.FakeProvider: payment was declined"}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ID := uuid.New().String()
+	p.authorizations[ID] = &FakeAuthorization{
+		Amount:   r.Fare.Amount,
+		Currency: r.Fare.Currency,
+	}
+
+	return ID, nil
+}
+
+// Capture records that the given amount was charged on an authorization.
+func (p *FakeProvider) Capture(authorizationID string, amount int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, err := p.find(authorizationID)
+	if err != nil {
+		return err
+	}
+
+	if a.Voided || a.Captured > 0 {
+		return InvalidOperationError{fmt.Sprintf("payment.FakeProvider: authorization \"%s\" can no longer be captured", authorizationID)}
+	}
+
+	if amount < 0 || amount > a.Amount {
+		return InvalidOperationError{fmt.Sprintf("payment.FakeProvider: cannot capture %d on authorization \"%s\" of %d", amount, authorizationID, a.Amount)}
+	}
+
+	a.Captured = amount
+
+	return nil
+}
+
+// Void records that an authorization was released.
+func (p *FakeProvider) Void(authorizationID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, err := p.find(authorizationID)
+	if err != nil {
+		return err
+	}
+
+	if a.Captured > 0 {
+		return InvalidOperationError{fmt.Sprintf("payment.FakeProvider: authorization \"%s\" was already captured", authorizationID)}
+	}
+
+	a.Voided = true
+
+	return nil
+}
+
+// Refund records that the given amount of a captured payment was given back.
+func (p *FakeProvider) Refund(authorizationID string, amount int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, err := p.find(authorizationID)
+	if err != nil {
+		return err
+	}
+
+	if amount < 0 || a.Refunded+amount > a.Captured {
+		return InvalidOperationError{fmt.Sprintf("payment.FakeProvider: cannot refund %d on authorization \"%s\"", amount, authorizationID)}
+	}
+
+	a.Refunded += amount
+
+	return nil
+}
+
+// Authorization retrieves the state of the authorization with the given ID,
+// if it exists.
+func (p *FakeProvider) Authorization(authorizationID string) (FakeAuthorization, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, ok := p.authorizations[authorizationID]
+	if !ok {
+		return FakeAuthorization{}, false
+	}
+
+	return *a, true
+}
+
+func (p *FakeProvider) find(authorizationID string) (*FakeAuthorization, error) {
+	a, ok := p.authorizations[authorizationID]
+	if !ok {
+		return nil, NotFoundError{fmt.Sprintf("payment.FakeProvider: no authorization found with ID \"%s\"", authorizationID)}
+	}
+
+	return a, nil
+}
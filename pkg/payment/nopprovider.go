@@ -0,0 +1,46 @@
+package payment
+
+import (
+	"fmt"
+	"log"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"github.com/google/uuid"
+)
+
+// A NopProvider is a provider that charges nothing and only logs the
+// operations it is asked to perform. It is used as long as no payment
+// processor is integrated, so every operation succeeds, whether or not the
+// service was restarted since the authorization was made.
+type NopProvider struct{}
+
+// Authorize logs the authorization of the reservation's fare and returns a new
+// authorization ID.
+func (p NopProvider) Authorize(r *entity.Reservation) (string, error) {
+	if r == nil || r.Fare == nil {
+		return "", fmt.Errorf("payment.NopProvider: reservation has no fare")
+	}
+
+	ID := uuid.New().String()
+	log.Printf("payment.NopProvider: authorized %d %s for reservation \"%s\" (authorization \"%s\")", r.Fare.Amount, r.Fare.Currency, r.ID, ID)
+
+	return ID, nil
+}
+
+// Capture logs that the given amount was charged on an authorization.
+func (p NopProvider) Capture(authorizationID string, amount int) error {
+	log.Printf("payment.NopProvider: captured %d on authorization \"%s\"", amount, authorizationID)
+	return nil
+}
+
+// Void logs that an authorization was released.
+func (p NopProvider) Void(authorizationID string) error {
+	log.Printf("payment.NopProvider: voided authorization \"%s\"", authorizationID)
+	return nil
+}
+
+// Refund logs that the given amount of a captured payment was given back.
+func (p NopProvider) Refund(authorizationID string, amount int) error {
+	log.Printf("payment.NopProvider: refunded %d on authorization \"%s\"", amount, authorizationID)
+	return nil
+}
@@ -0,0 +1,28 @@
+package payment
+
+import (
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Provider is an interface representing the ability to charge passengers for
+// their reservations through a payment processor.
+//
+// Payments follow an authorize and capture flow: the fare is authorized when a
+// reservation is booked, and captured once the trip is completed. An
+// authorization that is no longer needed is voided, and a captured payment can
+// be refunded.
+type Provider interface {
+	// Authorize places a hold on the reservation's fare and returns the
+	// authorization's unique identifier.
+	Authorize(r *entity.Reservation) (string, error)
+
+	// Capture charges the given amount on an authorization. The rest of the
+	// authorized amount is released.
+	Capture(authorizationID string, amount int) error
+
+	// Void releases an authorization without charging anything.
+	Void(authorizationID string) error
+
+	// Refund gives back the given amount of a captured payment.
+	Refund(authorizationID string, amount int) error
+}
@@ -0,0 +1,13 @@
+package pricing
+
+// An InvalidSegmentError is an error that represents that the segment of a
+// trip between a reservation's source and destination cannot be priced, for
+// example because a stop is not part of the trip or the destination comes
+// before the source.
+type InvalidSegmentError struct {
+	msg string
+}
+
+func (e InvalidSegmentError) Error() string {
+	return e.msg
+}
@@ -0,0 +1,122 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+)
+
+// DefaultCurrency represents the currency used when a trip does not specify
+// one.
+const DefaultCurrency = "CAD"
+
+// UseCase is an interface representing the ability to compute the fare of a
+// reservation.
+type UseCase interface {
+	Quote(r *entity.Reservation) (*entity.Fare, error)
+}
+
+// A Service handles the business logic related to pricing reservations.
+type Service struct {
+	tripService trip.UseCase
+}
+
+// NewService creates a pricing service that computes fares using the trips
+// retrieved through the trip service.
+func NewService(tripService trip.UseCase) *Service {
+	return &Service{tripService}
+}
+
+// Quote computes the fare of the given reservation from the trip's price per
+// seat, the portion of the trip covered between the reservation's source and
+// destination, and the number of seats reserved.
+func (s *Service) Quote(r *entity.Reservation) (*entity.Fare, error) {
+	if r == nil {
+		return nil, fmt.Errorf("pricing.Service: reservation is nil")
+	}
+
+	err := r.ValidateSegment()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.tripService.FindByID(r.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compute(t, r)
+}
+
+// Compute computes the fare of the given reservation on the given trip.
+func Compute(t *entity.Trip, r *entity.Reservation) (*entity.Fare, error) {
+	if t == nil {
+		return nil, fmt.Errorf("pricing: trip is nil")
+	}
+
+	if r == nil {
+		return nil, fmt.Errorf("pricing: reservation is nil")
+	}
+
+	// Stops the trip-service sent as null are left out, so that they neither
+	// count as legs nor break the distances between the other stops.
+	route := &entity.Trip{ID: t.ID}
+	for _, stop := range t.Stops {
+		if stop != nil {
+			route.Stops = append(route.Stops, stop)
+		}
+	}
+
+	source := route.StopIndex(r.SourceID)
+	if source < 0 {
+		return nil, InvalidSegmentError{fmt.Sprintf("pricing: source \"%s\" is not a stop of trip \"%s\"", r.SourceID, t.ID)}
+	}
+
+	destination := route.StopIndex(r.DestinationID)
+	if destination < 0 {
+		return nil, InvalidSegmentError{fmt.Sprintf("pricing: destination \"%s\" is not a stop of trip \"%s\"", r.DestinationID, t.ID)}
+	}
+
+	if destination <= source {
+		return nil, InvalidSegmentError{"pricing: destination must come after source"}
+	}
+
+	currency := t.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	ratio := segmentRatio(route.Stops, source, destination)
+	amount := int(math.Round(float64(t.PricePerSeat*r.Seats) * ratio))
+
+	return &entity.Fare{Amount: amount, Currency: currency}, nil
+}
+
+// segmentRatio returns the portion of the trip covered between the stops at
+// the given positions, none of which can be nil. It is based on the distance
+// between the stops when they all have a location, and on the number of legs
+// otherwise.
+func segmentRatio(stops []*entity.Stop, source int, destination int) float64 {
+	legs := float64(len(stops) - 1)
+
+	var total, segment float64
+	for i := 1; i < len(stops); i++ {
+		if stops[i-1].Point == nil || stops[i].Point == nil {
+			return float64(destination-source) / legs
+		}
+
+		d := stops[i-1].Point.DistanceTo(stops[i].Point)
+		total += d
+		if i > source && i <= destination {
+			segment += d
+		}
+	}
+
+	if total == 0 {
+		return float64(destination-source) / legs
+	}
+
+	return segment / total
+}
@@ -0,0 +1,96 @@
+package pricing
+
+import (
+	"testing"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+func TestComputeByLegs(t *testing.T) {
+	tr := &entity.Trip{
+		ID:           "trip",
+		PricePerSeat: 1000,
+		Stops:        []*entity.Stop{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}},
+	}
+	r := &entity.Reservation{TripID: "trip", SourceID: "a", DestinationID: "c", Seats: 3}
+
+	fare, err := Compute(tr, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two of the three legs, for three seats.
+	if fare.Amount != 2000 || fare.Currency != DefaultCurrency {
+		t.Errorf("got %d %s, want 2000 %s", fare.Amount, fare.Currency, DefaultCurrency)
+	}
+}
+
+func TestComputeByDistance(t *testing.T) {
+	tr := &entity.Trip{
+		ID:           "trip",
+		PricePerSeat: 900,
+		Currency:     "USD",
+		Stops: []*entity.Stop{
+			{ID: "a", Point: &entity.Point{Latitude: 0, Longitude: 0}},
+			{ID: "b", Point: &entity.Point{Latitude: 0, Longitude: 2}},
+			{ID: "c", Point: &entity.Point{Latitude: 0, Longitude: 3}},
+		},
+	}
+	r := &entity.Reservation{TripID: "trip", SourceID: "b", DestinationID: "c", Seats: 1}
+
+	fare, err := Compute(tr, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last leg is a third of the distance.
+	if fare.Amount != 300 || fare.Currency != "USD" {
+		t.Errorf("got %d %s, want 300 USD", fare.Amount, fare.Currency)
+	}
+}
+
+func TestComputeIgnoresNilStops(t *testing.T) {
+	tr := &entity.Trip{
+		ID:           "trip",
+		PricePerSeat: 1000,
+		Stops:        []*entity.Stop{{ID: "a"}, nil, {ID: "b"}, {ID: "c"}},
+	}
+	r := &entity.Reservation{TripID: "trip", SourceID: "a", DestinationID: "b", Seats: 1}
+
+	fare, err := Compute(tr, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fare.Amount != 500 {
+		t.Errorf("got %d, want 500", fare.Amount)
+	}
+}
+
+func TestComputeRefusesInvalidSegments(t *testing.T) {
+	tr := &entity.Trip{
+		ID:           "trip",
+		PricePerSeat: 1000,
+		Stops:        []*entity.Stop{{ID: "a"}, {ID: "b"}},
+	}
+
+	tests := []struct {
+		name        string
+		source      entity.ID
+		destination entity.ID
+	}{
+		{"unknown source", "x", "b"},
+		{"unknown destination", "a", "x"},
+		{"destination before source", "b", "a"},
+		{"same stop", "a", "a"},
+	}
+
+	for _, tt := range tests {
+		r := &entity.Reservation{TripID: "trip", SourceID: tt.source, DestinationID: tt.destination, Seats: 1}
+
+		_, err := Compute(tr, r)
+		if _, ok := err.(InvalidSegmentError); !ok {
+			t.Errorf("%s: got error %v, want an InvalidSegmentError", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// SweepInterval represents how often the buckets that are full again are
+// removed from a memory store. A full bucket is the same as a missing one.
+const SweepInterval = time.Minute
+
+// A MemoryStore is a store that keeps the token buckets in memory, so each
+// instance of the service limits its clients on its own.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	*Bucket
+
+	// fullAt is when the bucket is full again.
+	fullAt time.Time
+}
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket), lastSweep: time.Now()}
+}
+
+// Take takes a token from the bucket with the given key, refilled according
+// to the rule.
+func (s *MemoryStore) Take(key string, rule *Rule) (*Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= SweepInterval {
+		s.sweep(now)
+	}
+
+	var b *Bucket
+	if mb, ok := s.buckets[key]; ok {
+		b = mb.Bucket
+	}
+
+	b, res := rule.Take(b, now)
+	s.buckets[key] = &memoryBucket{b, now.Add(res.Reset)}
+
+	return res, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, mb := range s.buckets {
+		if !now.Before(mb.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
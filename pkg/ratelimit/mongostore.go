@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/db"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// MaxAttempts represents how many times a mongo store tries to take a token
+// from a bucket that is modified concurrently before giving up.
+const MaxAttempts = 5
+
+// A MongoStore is a store that keeps the token buckets in a MongoDB
+// collection, so that every instance of the service shares them. Buckets
+// expire once they are full again, which is the same as a missing bucket.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+type document struct {
+	Key       string    `bson:"_id"`
+	Tokens    float64   `bson:"tokens"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	Version   int       `bson:"version"`
+}
+
+// NewMongoStore creates a store for a MongoDB collection.
+func NewMongoStore(collection *mongo.Collection) (*MongoStore, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("ratelimit.MongoStore: collection is nil")
+	}
+
+	return &MongoStore{collection}, nil
+}
+
+// Take takes a token from the bucket with the given key, refilled according
+// to the rule. The bucket is only updated if it was not modified since it was
+// retrieved, otherwise taking the token is tried again.
+//
+// A bucket that is still modified concurrently after MaxAttempts is being
+// taken from faster than it can be updated, so the request is denied rather
+// than let through without taking a token.
+func (s *MongoStore) Take(key string, rule *Rule) (*Result, error) {
+	for attempt := 0; attempt < MaxAttempts; attempt++ {
+		var d document
+		err := s.collection.FindOne(context.TODO(), bson.D{{Key: "_id", Value: key}}).Decode(&d)
+		found := err == nil
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("ratelimit.MongoStore: failed to find bucket \"%s\" (%s)", key, err)
+		}
+
+		var b *Bucket
+		if found {
+			b = &Bucket{d.Tokens, d.UpdatedAt}
+		}
+
+		now := time.Now().UTC()
+		b, res := rule.Take(b, now)
+		next := document{key, b.Tokens, b.UpdatedAt, now.Add(res.Reset), d.Version + 1}
+
+		if !found {
+			_, err = s.collection.InsertOne(context.TODO(), next)
+			if db.IsDuplicateKeyError(err) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("ratelimit.MongoStore: failed to create bucket \"%s\" (%s)", key, err)
+			}
+
+			return res, nil
+		}
+
+		filter := bson.D{
+			{Key: "_id", Value: key},
+			{Key: "version", Value: d.Version},
+		}
+		update := bson.D{
+			{Key: "$set", Value: next},
+		}
+		resp, err := s.collection.UpdateOne(context.TODO(), filter, update)
+		if err != nil {
+			return nil, fmt.Errorf("ratelimit.MongoStore: failed to update bucket \"%s\" (%s)", key, err)
+		}
+
+		if resp.MatchedCount > 0 {
+			return res, nil
+		}
+	}
+
+	return &Result{
+		Rule:       rule,
+		Reset:      rule.Period,
+		RetryAfter: seconds(rule.Period.Seconds() / float64(rule.Limit)),
+	}, nil
+}
@@ -0,0 +1,218 @@
+// Package ratelimit limits how often clients can make requests, with a token
+// bucket per client and route: a bucket holds as many tokens as requests can
+// be made at once, each request takes a token, and the bucket is refilled at a
+// steady rate.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/identity"
+)
+
+// DefaultRoute is the name of the rule applied to the routes that have no
+// rule of their own.
+const DefaultRoute = "default"
+
+// DefaultRules represents the rules applied when none are configured. Booking,
+// cancelling and holding seats are limited the most, since repeating them
+// grabs and releases seats on trips.
+const DefaultRules = "default=300/1m,createReservation=10/1m,createReservationBatch=5/1m,cancelReservation=10/1m,cancelReservationBatch=5/1m,createHold=10/1m,releaseHold=10/1m"
+
+// A Rule describes the token bucket of a route: Limit requests can be made at
+// once, and the bucket is refilled at Limit requests per Period.
+type Rule struct {
+	Limit  int
+	Period time.Duration
+}
+
+// IsUnlimited returns whether or not the rule lets any number of requests be
+// made.
+func (r *Rule) IsUnlimited() bool {
+	return r == nil || r.Limit <= 0 || r.Period <= 0
+}
+
+// A Bucket is the state of a token bucket.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// A Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Rule *Rule
+
+	// Allowed is whether or not a token was taken, and so whether or not the
+	// request can be made.
+	Allowed bool
+
+	// Remaining is the number of requests that can still be made at once.
+	Remaining int
+
+	// Reset is how long it takes for the bucket to be full again.
+	Reset time.Duration
+
+	// RetryAfter is how long it takes for a token to be available, when the
+	// request is not allowed.
+	RetryAfter time.Duration
+}
+
+// Take refills the bucket according to the rule since it was last updated,
+// and takes a token from it if there is one. A nil bucket is full. It returns
+// the new state of the bucket, and the result of taking the token.
+func (r *Rule) Take(b *Bucket, now time.Time) (*Bucket, *Result) {
+	// rate is the number of tokens the bucket is refilled with per second.
+	rate := float64(r.Limit) / r.Period.Seconds()
+
+	tokens := float64(r.Limit)
+	if b != nil {
+		elapsed := now.Sub(b.UpdatedAt).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		tokens = math.Min(tokens, b.Tokens+elapsed*rate)
+	}
+
+	res := &Result{Rule: r}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	res.Remaining = int(tokens)
+	res.Reset = seconds((float64(r.Limit) - tokens) / rate)
+
+	return &Bucket{tokens, now}, res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// ParseRules parses rules by route, separated by commas, in the form
+// route=limit/period (ex. "default=300/1m,createReservation=10/1m"). A limit
+// of 0 lets any number of requests be made on the route.
+func ParseRules(s string) (map[string]*Rule, error) {
+	rules := map[string]*Rule{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("ratelimit: rule \"%s\" must be in the form route=limit/period", part)
+		}
+
+		lp := strings.SplitN(kv[1], "/", 2)
+		if len(lp) != 2 {
+			return nil, fmt.Errorf("ratelimit: rule \"%s\" must be in the form route=limit/period", part)
+		}
+
+		limit, err := strconv.Atoi(lp[0])
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("ratelimit: limit of rule \"%s\" must be an integer of at least 0", part)
+		}
+
+		period, err := time.ParseDuration(lp[1])
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("ratelimit: period of rule \"%s\" must be a positive duration (ex. 1m)", part)
+		}
+
+		rules[kv[0]] = &Rule{limit, period}
+	}
+
+	return rules, nil
+}
+
+// A Store stores the token buckets of the clients. Taking a token must be
+// atomic, even when the store is shared by several instances of the service.
+type Store interface {
+	// Take takes a token from the bucket with the given key, refilled
+	// according to the rule.
+	Take(key string, rule *Rule) (*Result, error)
+}
+
+// Config contains the configuration of a limiter.
+type Config struct {
+	// Rules contains the rule of each route, by name. The rule named
+	// DefaultRoute is applied to the routes that have no rule of their own.
+	Rules map[string]*Rule
+
+	// TrustForwardedFor specifies whether or not the client's IP address is
+	// the last one of the X-Forwarded-For header, which is only safe behind a
+	// proxy that appends to it, rather than the address the request came
+	// from.
+	TrustForwardedFor bool
+}
+
+// A Limiter limits how often each client can make requests on each route.
+// Clients are identified by the sub ID of the authenticated user, or by IP
+// address for requests that are not made on behalf of a user.
+type Limiter struct {
+	store Store
+	conf  *Config
+}
+
+// NewLimiter creates a limiter that stores the token buckets of the clients
+// in the given store.
+func NewLimiter(store Store, conf *Config) (*Limiter, error) {
+	if store == nil {
+		return nil, fmt.Errorf("ratelimit: store is nil")
+	}
+
+	if conf == nil {
+		return nil, fmt.Errorf("ratelimit: missing configuration")
+	}
+
+	return &Limiter{store, conf}, nil
+}
+
+// Allow takes a token from the bucket of the client making the request on the
+// route. It returns a nil result when the route is unlimited.
+func (l *Limiter) Allow(route string, r *http.Request) (*Result, error) {
+	rule, ok := l.conf.Rules[route]
+	if !ok {
+		rule = l.conf.Rules[DefaultRoute]
+	}
+
+	if rule.IsUnlimited() {
+		return nil, nil
+	}
+
+	return l.store.Take(route+":"+l.client(r), rule)
+}
+
+// client identifies the client making the request.
+func (l *Limiter) client(r *http.Request) string {
+	userInfo, err := identity.FromContext(r.Context())
+	if err == nil && userInfo.SubID != "" {
+		return "user:" + userInfo.SubID
+	}
+
+	return "ip:" + l.clientIP(r)
+}
+
+func (l *Limiter) clientIP(r *http.Request) string {
+	if l.conf.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			addrs := strings.Split(forwarded, ",")
+			return strings.TrimSpace(addrs[len(addrs)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
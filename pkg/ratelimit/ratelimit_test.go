@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	rule := &Rule{Limit: 2, Period: 2 * time.Second}
+	now := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	b, res := rule.Take(nil, now)
+	if !res.Allowed || res.Remaining != 1 || res.Reset != time.Second {
+		t.Errorf("first request: got allowed %t, remaining %d, reset %s", res.Allowed, res.Remaining, res.Reset)
+	}
+
+	b, res = rule.Take(b, now)
+	if !res.Allowed || res.Remaining != 0 || res.Reset != 2*time.Second {
+		t.Errorf("second request: got allowed %t, remaining %d, reset %s", res.Allowed, res.Remaining, res.Reset)
+	}
+
+	b, res = rule.Take(b, now.Add(500*time.Millisecond))
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Errorf("third request: got allowed %t, retry after %s", res.Allowed, res.RetryAfter)
+	}
+
+	_, res = rule.Take(b, now.Add(time.Second))
+	if !res.Allowed {
+		t.Errorf("request after refill: got allowed %t", res.Allowed)
+	}
+}
+
+func TestTakeDoesNotOverfill(t *testing.T) {
+	rule := &Rule{Limit: 2, Period: time.Second}
+	now := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	b, _ := rule.Take(nil, now)
+	_, res := rule.Take(b, now.Add(time.Hour))
+	if res.Remaining != 1 {
+		t.Errorf("got remaining %d, want 1", res.Remaining)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(DefaultRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, ok := rules["createReservation"]
+	if !ok || rule.Limit != 10 || rule.Period != time.Minute {
+		t.Errorf("got createReservation rule %+v", rule)
+	}
+
+	rules, err = ParseRules("default=0/1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rules[DefaultRoute].IsUnlimited() {
+		t.Errorf("rule with a limit of 0 is not unlimited")
+	}
+
+	for _, s := range []string{"default", "=1/1m", "default=1", "default=x/1m", "default=-1/1m", "default=1/x", "default=1/0s"} {
+		_, err := ParseRules(s)
+		if err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	s := NewMemoryStore()
+	rule := &Rule{Limit: 1, Period: time.Hour}
+
+	res, err := s.Take("a", rule)
+	if err != nil || !res.Allowed {
+		t.Fatalf("first request: got allowed %t (%v)", res.Allowed, err)
+	}
+
+	res, err = s.Take("a", rule)
+	if err != nil || res.Allowed {
+		t.Errorf("second request: got allowed %t (%v)", res.Allowed, err)
+	}
+
+	res, err = s.Take("b", rule)
+	if err != nil || !res.Allowed {
+		t.Errorf("request of another client: got allowed %t (%v)", res.Allowed, err)
+	}
+}
+
+type keyStore struct {
+	keys []string
+}
+
+func (s *keyStore) Take(key string, rule *Rule) (*Result, error) {
+	s.keys = append(s.keys, key)
+	return &Result{Rule: rule, Allowed: true}, nil
+}
+
+func TestLimiterAllow(t *testing.T) {
+	store := &keyStore{}
+	l, err := NewLimiter(store, &Config{
+		Rules: map[string]*Rule{
+			DefaultRoute: {Limit: 10, Period: time.Minute},
+			"unlimited":  {Limit: 0, Period: time.Minute},
+		},
+		TrustForwardedFor: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "192.0.2.1, 192.0.2.2")
+
+	res, err := l.Allow("unlimited", r)
+	if err != nil || res != nil {
+		t.Errorf("unlimited route: got %+v (%v), want no result", res, err)
+	}
+
+	_, err = l.Allow("other", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.keys) != 1 || store.keys[0] != "other:ip:192.0.2.2" {
+		t.Errorf("got keys %v, want [other:ip:192.0.2.2]", store.keys)
+	}
+}
@@ -0,0 +1,319 @@
+package reconciliation
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"azure.com/ecovo/reservation-service/pkg/reservation"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+)
+
+const (
+	// DefaultInterval represents how often reservations are reconciled by
+	// default.
+	DefaultInterval = 1 * time.Hour
+
+	// GracePeriod represents how long a reservation that was just booked or
+	// cancelled is left alone, since the request that changes it may still
+	// be updating the trip-service.
+	GracePeriod = 5 * time.Minute
+)
+
+// A Kind describes how a reservation differs between the database and the
+// trip-service.
+type Kind string
+
+const (
+	// KindMissingOnTrip is the kind of discrepancy where an active
+	// reservation is not registered on its trip.
+	KindMissingOnTrip = Kind("missing-on-trip")
+
+	// KindStaleOnTrip is the kind of discrepancy where a reservation that is
+	// no longer active is still registered on its trip.
+	KindStaleOnTrip = Kind("stale-on-trip")
+
+	// KindUnknownOnTrip is the kind of discrepancy where a reservation that
+	// does not exist is registered on a trip.
+	KindUnknownOnTrip = Kind("unknown-on-trip")
+
+	// KindSeatsMismatch is the kind of discrepancy where a reservation is
+	// registered on its trip with a different number of seats.
+	KindSeatsMismatch = Kind("seats-mismatch")
+)
+
+// A Discrepancy is a difference between a reservation in the database and the
+// trip-service's view of it.
+type Discrepancy struct {
+	TripID        entity.ID     `json:"tripId"`
+	ReservationID entity.ID     `json:"reservationId"`
+	Kind          Kind          `json:"kind"`
+	Status        entity.Status `json:"status,omitempty"`
+	Seats         int           `json:"seats"`
+	TripSeats     int           `json:"tripSeats"`
+	Repaired      bool          `json:"repaired"`
+	Error         string        `json:"error,omitempty"`
+
+	reservation     *entity.Reservation
+	tripReservation *entity.Reservation
+}
+
+// A TripError is an error that prevented a trip from being reconciled.
+type TripError struct {
+	TripID entity.ID `json:"tripId"`
+	Error  string    `json:"error"`
+}
+
+// A Report is the result of a reconciliation.
+type Report struct {
+	DryRun        bool           `json:"dryRun"`
+	StartedAt     time.Time      `json:"startedAt"`
+	FinishedAt    time.Time      `json:"finishedAt"`
+	Trips         int            `json:"trips"`
+	Discrepancies []*Discrepancy `json:"discrepancies"`
+	Errors        []*TripError   `json:"errors"`
+}
+
+// UseCase is an interface representing the ability to reconcile the
+// reservations in the database with the trip-service.
+type UseCase interface {
+	Reconcile(tripIDs []entity.ID, repair bool) (*Report, error)
+	ReconcileAll(repair bool) (*Report, error)
+}
+
+// A Service compares the reservations in the database with the ones registered
+// on trips in the trip-service.
+//
+// Since registering or cancelling a reservation is not atomic across both
+// services, they can drift apart when a request fails halfway. The database
+// is considered to be the source of truth when repairing a discrepancy.
+//
+// The seats held on a trip are registered under the ID of their hold, so the
+// holds are not reported as unknown reservations.
+type Service struct {
+	repo        reservation.Repository
+	holdRepo    hold.Repository
+	tripService trip.UseCase
+}
+
+// NewService creates a reconciliation service that compares the reservations
+// and holds in the repositories with the ones registered through the trip
+// service.
+func NewService(repo reservation.Repository, holdRepo hold.Repository, tripService trip.UseCase) *Service {
+	return &Service{repo, holdRepo, tripService}
+}
+
+// Reconcile compares the reservations made on the trips with the given IDs
+// with the trip-service's view and reports the discrepancies. They are only
+// repaired when repair is true; otherwise, the reconciliation is a dry run.
+func (s *Service) Reconcile(tripIDs []entity.ID, repair bool) (*Report, error) {
+	report := &Report{
+		DryRun:        !repair,
+		StartedAt:     time.Now(),
+		Discrepancies: []*Discrepancy{},
+		Errors:        []*TripError{},
+	}
+
+	for _, tripID := range tripIDs {
+		discrepancies, err := s.compare(tripID)
+		if err != nil {
+			report.Errors = append(report.Errors, &TripError{tripID, err.Error()})
+			continue
+		}
+
+		if repair {
+			for _, d := range discrepancies {
+				err = s.repair(d)
+				if err != nil {
+					d.Error = err.Error()
+				} else {
+					d.Repaired = true
+				}
+			}
+		}
+
+		report.Trips++
+		report.Discrepancies = append(report.Discrepancies, discrepancies...)
+	}
+
+	report.FinishedAt = time.Now()
+
+	return report, nil
+}
+
+// ReconcileAll reconciles every trip on which reservations were made.
+func (s *Service) ReconcileAll(repair bool) (*Report, error) {
+	tripIDs, err := s.repo.FindTripIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Reconcile(tripIDs, repair)
+}
+
+// compare finds the discrepancies between the reservations made on the trip
+// with the given ID and the ones registered on it in the trip-service.
+func (s *Service) compare(tripID entity.ID) ([]*Discrepancy, error) {
+	reservations, err := s.repo.FindByTripID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	tripReservations, err := s.tripService.FindReservations(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[entity.ID]*entity.Reservation)
+	for _, tr := range tripReservations {
+		registered[tr.ID] = tr
+	}
+
+	now := time.Now()
+
+	discrepancies := []*Discrepancy{}
+	for _, res := range reservations {
+		tr, ok := registered[res.ID]
+		delete(registered, res.ID)
+
+		if isRecent(res, now) {
+			continue
+		}
+
+		d := &Discrepancy{
+			TripID:          tripID,
+			ReservationID:   res.ID,
+			Status:          res.Status,
+			Seats:           res.Seats,
+			reservation:     res,
+			tripReservation: tr,
+		}
+		if ok {
+			d.TripSeats = tr.Seats
+		}
+
+		if res.IsActive() && !ok {
+			d.Kind = KindMissingOnTrip
+		} else if !res.IsActive() && ok {
+			d.Kind = KindStaleOnTrip
+		} else if res.IsActive() && tr.Seats != res.Seats {
+			d.Kind = KindSeatsMismatch
+		} else {
+			continue
+		}
+
+		discrepancies = append(discrepancies, d)
+	}
+
+	for _, tr := range tripReservations {
+		if _, ok := registered[tr.ID]; !ok {
+			continue
+		}
+
+		// A reservation being booked is registered on the trip, under the
+		// ID of its hold, before it is stored.
+		if createdAt, ok := creationTime(tr.ID); ok && now.Sub(createdAt) < GracePeriod {
+			continue
+		}
+
+		_, err := s.holdRepo.FindByID(tr.ID)
+		if err == nil {
+			continue
+		}
+
+		discrepancies = append(discrepancies, &Discrepancy{
+			TripID:          tripID,
+			ReservationID:   tr.ID,
+			Kind:            KindUnknownOnTrip,
+			TripSeats:       tr.Seats,
+			tripReservation: tr,
+		})
+	}
+
+	return discrepancies, nil
+}
+
+// isRecent returns whether or not the reservation was booked or cancelled
+// within the grace period, in which case the request that changed it may not
+// be done updating the trip-service.
+func isRecent(res *entity.Reservation, now time.Time) bool {
+	if res.Cancellation != nil && now.Sub(res.Cancellation.CancelledAt) < GracePeriod {
+		return true
+	}
+
+	createdAt, ok := creationTime(res.ID)
+
+	return ok && now.Sub(createdAt) < GracePeriod
+}
+
+// creationTime returns when the entity with the given ID was created, from the
+// timestamp of its object ID.
+func creationTime(ID entity.ID) (time.Time, bool) {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(binary.BigEndian.Uint32(objectID[0:4])), 0), true
+}
+
+// repair makes the trip-service match the database for the given
+// discrepancy.
+func (s *Service) repair(d *Discrepancy) error {
+	switch d.Kind {
+	case KindMissingOnTrip:
+		return s.tripService.RegisterReservation(d.reservation)
+	case KindStaleOnTrip, KindUnknownOnTrip:
+		return s.tripService.DeleteReservation(d.tripReservation)
+	case KindSeatsMismatch:
+		err := s.tripService.DeleteReservation(d.tripReservation)
+		if err != nil {
+			return err
+		}
+
+		return s.tripService.RegisterReservation(d.reservation)
+	default:
+		return fmt.Errorf("reconciliation.Service: unknown discrepancy kind \"%s\"", d.Kind)
+	}
+}
+
+// Schedule reconciles every trip at every interval, until the context is
+// done. The reports that contain discrepancies or errors are logged as JSON.
+//
+// Every instance of the service runs its own schedule, so the scheduled
+// reconciliations are dry runs: repairing the same discrepancies from several
+// instances at once would make them worse. They are repaired on demand.
+func Schedule(ctx context.Context, service UseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := service.ReconcileAll(false)
+			if err != nil {
+				log.Printf("reconciliation.Schedule: %s", err)
+				continue
+			}
+
+			if len(report.Discrepancies) == 0 && len(report.Errors) == 0 {
+				continue
+			}
+
+			b, err := json.Marshal(report)
+			if err != nil {
+				log.Printf("reconciliation.Schedule: failed to encode report (%s)", err)
+				continue
+			}
+			log.Printf("reconciliation.Schedule: %s", b)
+		}
+	}
+}
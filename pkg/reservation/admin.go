@@ -0,0 +1,76 @@
+package reservation
+
+import (
+	"context"
+	"log"
+
+	"azure.com/ecovo/reservation-service/pkg/audit"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+)
+
+// List retrieves the reservations selected by the criteria. Unlike
+// FindByTripID, it does not authorize the caller, so it is only meant for
+// operators.
+func (s *Service) List(ctx context.Context, c *Criteria) ([]*entity.Reservation, error) {
+	if c == nil {
+		c = &Criteria{}
+	}
+
+	return s.repo.Find(c)
+}
+
+// CancelAsPassenger cancels the reservation with the given ID as if its
+// passenger had, applying the cancellation policy. Unlike Delete, it does not
+// authorize the caller, so it is only meant for operators.
+func (s *Service) CancelAsPassenger(ctx context.Context, ID entity.ID) (*entity.Reservation, error) {
+	res, err := s.FindByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cancelAsPassenger(ctx, res, 0)
+}
+
+// ForceDelete removes the reservation with the given ID from the database,
+// whatever its status, without charging a cancellation fee. It is meant for
+// operators cleaning up reservations that cannot be cancelled normally.
+//
+// An authorized payment is voided first, so that the passenger is not left
+// with a hold on their fare, and the reservation is kept if it cannot be. A
+// payment that was already captured is left as it is.
+//
+// The seats of an active reservation are released on its trip, but failing to
+// do so does not prevent the reservation from being removed: the error is only
+// logged, and a reconciliation will report the reservation as unknown on its
+// trip.
+func (s *Service) ForceDelete(ctx context.Context, ID entity.ID) (*entity.Reservation, error) {
+	res, err := s.FindByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := res.Clone()
+
+	err = s.voidPayment(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.IsActive() {
+		err = s.tripService.DeleteReservation(res)
+		if err != nil {
+			requestID, _ := requestid.FromContext(ctx)
+			log.Printf("[Request ID=%s] reservation.Service: failed to release seats of reservation \"%s\" on trip \"%s\" (%s)", requestID, res.ID, res.TripID, err)
+		}
+	}
+
+	err = s.repo.Delete(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, audit.OperationForceDelete, before, nil)
+
+	return res, nil
+}
@@ -0,0 +1,83 @@
+package reservation
+
+import (
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/booking"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+)
+
+// enforceBookingPolicy ensures that the booking policy allows the passenger of
+// the reservation to book it, given the reservations they already made and
+// those of the same batch that are booked along with it.
+//
+// Only the reservations the policy counts are retrieved: the active ones, and
+// those cancelled within the cancellation window. The policy is enforced before
+// the reservation is stored rather than in the same write, so bookings made by
+// the same passenger at the same time can each be allowed and, together, exceed
+// the limits by the number of concurrent requests. Only a second reservation on
+// the same trip is refused atomically, by the repository.
+func (s *Service) enforceBookingPolicy(r *entity.Reservation, batch []*entity.Reservation) error {
+	now := time.Now()
+	reservations, err := s.repo.FindBookedBy(r.UserID, now.Add(-s.bookingPolicy.CancellationWindow()))
+	if err != nil {
+		return err
+	}
+	for _, b := range batch {
+		if b.UserID == r.UserID {
+			reservations = append(reservations, b)
+		}
+	}
+
+	err = s.bookingPolicy.AllowsBooking(r.UserID, reservations, now)
+	if err != nil {
+		return err
+	}
+
+	if s.bookingPolicy.AllowsOverlaps() {
+		return nil
+	}
+
+	trips := map[entity.ID]*entity.Trip{}
+	findTrip := func(ID entity.ID) (*entity.Trip, error) {
+		if t, ok := trips[ID]; ok {
+			return t, nil
+		}
+
+		t, err := s.tripService.FindByID(ID)
+		if err != nil {
+			return nil, err
+		}
+		trips[ID] = t
+
+		return t, nil
+	}
+
+	t, err := findTrip(r.TripID)
+	if err != nil {
+		return err
+	}
+
+	booked := []*booking.Ride{}
+	for _, res := range reservations {
+		// A second reservation on the same trip is refused as a duplicate
+		// when it is stored, rather than as an overlap.
+		if !res.IsActive() || res.TripID == r.TripID {
+			continue
+		}
+
+		// A trip that no longer exists cannot be ridden, so it cannot
+		// overlap with the reservation.
+		resTrip, err := findTrip(res.TripID)
+		if _, ok := err.(trip.NotFoundError); ok {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		booked = append(booked, booking.NewRide(res, resTrip))
+	}
+
+	return s.bookingPolicy.VerifyNoOverlap(booking.NewRide(r, t), booked)
+}
@@ -0,0 +1,97 @@
+package reservation
+
+import (
+	"sync"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/event"
+)
+
+// SubscriptionBuffer represents the number of changes that are buffered for a
+// subscriber. A subscriber that falls further behind is unsubscribed, so that
+// it does not hold back the others.
+const SubscriptionBuffer = 32
+
+// A Change is a notification that a reservation was created, modified or
+// cancelled.
+type Change struct {
+	Type        event.Type
+	Reservation *entity.Reservation
+}
+
+// A Filter selects the reservations whose changes a subscriber is notified
+// of.
+type Filter func(res *entity.Reservation) bool
+
+// A Subscription receives the changes made to the reservations selected by
+// its filter on C, until it is closed. C is closed when the subscription is
+// closed, or when the subscriber falls too far behind.
+type Subscription struct {
+	C <-chan *Change
+
+	c      chan *Change
+	filter Filter
+	bus    *Bus
+}
+
+// Close unsubscribes from the bus.
+func (sub *Subscription) Close() {
+	sub.bus.unsubscribe(sub)
+}
+
+// A Bus notifies the subscribers of the changes made to reservations within
+// the service. Changes made by other instances of the service are not
+// notified.
+type Bus struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBus creates a change notification bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe subscribes to the changes made to the reservations selected by
+// the filter.
+func (b *Bus) Subscribe(filter Filter) *Subscription {
+	c := make(chan *Change, SubscriptionBuffer)
+	sub := &Subscription{c, c, filter, b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs[sub] = struct{}{}
+
+	return sub
+}
+
+// Notify notifies the subscribers interested in the reservation of the
+// change. It never blocks: subscribers whose buffer is full are unsubscribed.
+func (b *Bus) Notify(c *Change) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+		if !sub.filter(c.Reservation) {
+			continue
+		}
+
+		select {
+		case sub.c <- c:
+		default:
+			delete(b.subs, sub)
+			close(sub.c)
+		}
+	}
+}
+
+func (b *Bus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
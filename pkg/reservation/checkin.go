@@ -0,0 +1,222 @@
+package reservation
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/audit"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/event"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
+)
+
+// checkInCodeAlphabet contains the characters a check-in code is made of.
+// Characters that are easily mistaken for one another are left out.
+const checkInCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// checkInCodeLength represents the number of characters in a check-in code.
+const checkInCodeLength = 6
+
+// newCheckInCode generates a random check-in code.
+func newCheckInCode() (string, error) {
+	b := make([]byte, checkInCodeLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("reservation.Service: failed to generate check-in code (%s)", err)
+	}
+
+	for i := range b {
+		b[i] = checkInCodeAlphabet[int(b[i])%len(checkInCodeAlphabet)]
+	}
+
+	return string(b), nil
+}
+
+// CheckIn checks in the passenger of a confirmed reservation on the driver's
+// trip, once the driver has scanned the passenger's check-in code. When the
+// version is not zero, the passenger is only checked in if the reservation
+// still has this version.
+func (s *Service) CheckIn(ctx context.Context, tripID entity.ID, ID entity.ID, code string, version int) (*entity.Reservation, error) {
+	if code == "" {
+		return nil, entity.NewFieldValidationError("code", "is missing")
+	}
+
+	res, err := s.findForDriver(ctx, tripID, ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanCheckIn(res)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkInPolicy.VerifyCode(res, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.checkIn(ctx, res, &entity.CheckIn{Method: entity.CheckInScan})
+}
+
+// SelfCheckIn checks in the passenger of a confirmed reservation, as long as
+// they are close enough to the stop they are picked up at and the trip is
+// about to leave it. When the version is not zero, the passenger is only
+// checked in if the reservation still has this version.
+func (s *Service) SelfCheckIn(ctx context.Context, ID entity.ID, point *entity.Point, version int) (*entity.Reservation, error) {
+	if point == nil {
+		return nil, entity.NewValidationError("point is missing")
+	}
+
+	res, err := s.FindByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = AuthorizePassenger(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkVersion(res, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCanCheckIn(res)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.tripService.FindByID(res.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkInPolicy.AllowsSelfCheckIn(t.FindStop(res.SourceID), t.DepartureFrom(res.SourceID), point, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.checkIn(ctx, res, &entity.CheckIn{Method: entity.CheckInSelf, Point: point})
+}
+
+// MarkNoShows marks the confirmed reservations whose passenger did not check
+// in before the trip left their stop as no-shows, and returns them. The
+// no-show fee of the cancellation policy is charged.
+func (s *Service) MarkNoShows(ctx context.Context) ([]*entity.Reservation, error) {
+	tripIDs, err := s.repo.FindActiveTripIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	marked := []*entity.Reservation{}
+	for _, tripID := range tripIDs {
+		t, err := s.tripService.FindByID(tripID)
+		if err != nil {
+			requestID, _ := requestid.FromContext(ctx)
+			log.Printf("[Request ID=%s] reservation.Service: failed to find trip \"%s\" to mark no-shows (%s)", requestID, tripID, err)
+			continue
+		}
+
+		reservations, err := s.repo.FindByTripID(tripID)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		for _, res := range reservations {
+			departure := t.DepartureFrom(res.SourceID)
+			if res.Status != entity.StatusConfirmed || res.CheckIn != nil || !s.checkInPolicy.IsNoShow(departure, now) {
+				continue
+			}
+
+			before := res.Clone()
+
+			res.Status = entity.StatusNoShow
+			res.Cancellation = s.cancellationPolicy.Evaluate(departure, now)
+
+			err = s.settlePayment(res)
+			if err != nil {
+				return nil, err
+			}
+
+			err = s.repo.Update(res)
+			if err != nil {
+				return nil, err
+			}
+
+			s.record(ctx, audit.OperationNoShow, before, res)
+			s.publish(ctx, event.ReservationModified, res)
+
+			marked = append(marked, res)
+		}
+	}
+
+	return marked, nil
+}
+
+// checkIn records the check-in on the reservation.
+func (s *Service) checkIn(ctx context.Context, res *entity.Reservation, c *entity.CheckIn) (*entity.Reservation, error) {
+	before := res.Clone()
+
+	c.CheckedInAt = time.Now()
+	res.CheckIn = c
+
+	err := s.repo.Update(res)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, audit.OperationCheckIn, before, res)
+	s.publish(ctx, event.ReservationModified, res)
+
+	return res, nil
+}
+
+// checkCanCheckIn ensures that the passenger of the reservation can check in.
+func checkCanCheckIn(res *entity.Reservation) error {
+	if res.Status != entity.StatusConfirmed {
+		return InvalidStatusError{fmt.Sprintf("reservation.Service: reservation with ID \"%s\" is not confirmed", res.ID)}
+	}
+
+	if res.CheckIn != nil {
+		return InvalidStatusError{fmt.Sprintf("reservation.Service: passenger of reservation with ID \"%s\" already checked in", res.ID)}
+	}
+
+	return nil
+}
+
+// AuthorizePassenger ensures that the authenticated user is the passenger of
+// the reservation.
+func AuthorizePassenger(ctx context.Context, res *entity.Reservation) error {
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil || userInfo.SubID == "" || userInfo.SubID != res.UserID.Hex() {
+		return ForbiddenError{fmt.Sprintf("reservation: only the passenger of reservation \"%s\" is allowed", res.ID)}
+	}
+
+	return nil
+}
+
+// WatchNoShows marks the passengers who did not check in as no-shows at every
+// interval, until the context is done.
+func WatchNoShows(ctx context.Context, service UseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := service.MarkNoShows(ctx)
+			if err != nil {
+				log.Printf("reservation.WatchNoShows: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/audit"
+	"azure.com/ecovo/reservation-service/pkg/cancellation"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/event"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+)
+
+// FindByTripID retrieves the reservations made on the trip with the given ID.
+// Only the trip's driver can see them.
+func (s *Service) FindByTripID(ctx context.Context, tripID entity.ID) ([]*entity.Reservation, error) {
+	_, err := s.authorizeDriver(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByTripID(tripID)
+}
+
+// Accept confirms a pending reservation on the driver's trip. When the version
+// is not zero, the reservation is only confirmed if it still has this version.
+func (s *Service) Accept(ctx context.Context, tripID entity.ID, ID entity.ID, version int) (*entity.Reservation, error) {
+	res, err := s.findForDriver(ctx, tripID, ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Status != entity.StatusPending {
+		return nil, InvalidStatusError{fmt.Sprintf("reservation.Service: reservation with ID \"%s\" is not pending", ID)}
+	}
+
+	before := res.Clone()
+
+	res.Status = entity.StatusConfirmed
+
+	err = s.repo.Update(res)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, audit.OperationAccept, before, res)
+	s.publish(ctx, event.ReservationModified, res)
+
+	return res, nil
+}
+
+// Reject refuses a pending reservation on the driver's trip for the given
+// reason. Its seats are released and the passenger is not charged. When the
+// version is not zero, the reservation is only rejected if it still has this
+// version.
+func (s *Service) Reject(ctx context.Context, tripID entity.ID, ID entity.ID, reason string, version int) (*entity.Reservation, error) {
+	if reason == "" {
+		return nil, entity.NewFieldValidationError("reason", "is missing")
+	}
+
+	res, err := s.findForDriver(ctx, tripID, ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Status != entity.StatusPending {
+		return nil, InvalidStatusError{fmt.Sprintf("reservation.Service: reservation with ID \"%s\" is not pending", ID)}
+	}
+
+	c := &entity.Cancellation{
+		Rule:        cancellation.RuleRejected,
+		Reason:      reason,
+		CancelledAt: time.Now(),
+	}
+
+	return s.cancel(ctx, res, entity.StatusRejected, c, audit.OperationReject)
+}
+
+// CancelByDriver cancels a passenger's reservation on the driver's trip for
+// the given reason. Its seats are released and the passenger is not charged.
+// When the version is not zero, the reservation is only cancelled if it still
+// has this version.
+func (s *Service) CancelByDriver(ctx context.Context, tripID entity.ID, ID entity.ID, reason string, version int) (*entity.Reservation, error) {
+	if reason == "" {
+		return nil, entity.NewFieldValidationError("reason", "is missing")
+	}
+
+	res, err := s.findForDriver(ctx, tripID, ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.IsActive() {
+		return nil, InvalidStatusError{fmt.Sprintf("reservation.Service: reservation with ID \"%s\" is already %s", ID, res.Status)}
+	}
+
+	c := &entity.Cancellation{
+		Rule:        cancellation.RuleDriver,
+		Reason:      reason,
+		CancelledAt: time.Now(),
+	}
+
+	return s.cancel(ctx, res, entity.StatusCancelledByDriver, c, audit.OperationCancelByDriver)
+}
+
+// authorizeDriver ensures that the authenticated user is the driver of the
+// trip with the given ID, and returns the trip.
+func (s *Service) authorizeDriver(ctx context.Context, tripID entity.ID) (*entity.Trip, error) {
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil || userInfo.SubID == "" {
+		return nil, ForbiddenError{"reservation.Service: only the trip's driver can manage its reservations"}
+	}
+
+	t, err := s.tripService.FindByID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.DriverID.Hex() != userInfo.SubID {
+		return nil, ForbiddenError{fmt.Sprintf("reservation.Service: user \"%s\" is not the driver of trip \"%s\"", userInfo.SubID, tripID)}
+	}
+
+	return t, nil
+}
+
+// authorizeParticipant ensures that the authenticated user is either the
+// passenger of the reservation or the driver of its trip.
+func (s *Service) authorizeParticipant(ctx context.Context, res *entity.Reservation) error {
+	if AuthorizePassenger(ctx, res) == nil {
+		return nil
+	}
+
+	_, err := s.authorizeDriver(ctx, res.TripID)
+	if _, ok := err.(ForbiddenError); ok {
+		return ForbiddenError{fmt.Sprintf("reservation.Service: only the passenger and the driver can see reservation \"%s\"", res.ID)}
+	}
+
+	return err
+}
+
+// findForDriver retrieves a reservation made on the trip with the given ID,
+// as long as the authenticated user is the trip's driver and the reservation
+// has the given version, unless it is zero.
+func (s *Service) findForDriver(ctx context.Context, tripID entity.ID, ID entity.ID, version int) (*entity.Reservation, error) {
+	_, err := s.authorizeDriver(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.FindByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.TripID != tripID {
+		return nil, NotFoundError{fmt.Sprintf("reservation.Service: no reservation found with ID \"%s\" on trip \"%s\"", ID, tripID)}
+	}
+
+	err = checkVersion(res, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -18,3 +18,57 @@ type AlreadyExistsError struct {
 func (e AlreadyExistsError) Error() string {
 	return e.msg
 }
+
+// An InvalidStatusError is an error that represents that an operation cannot
+// be performed on a reservation because of its current status.
+type InvalidStatusError struct {
+	msg string
+}
+
+func (e InvalidStatusError) Error() string {
+	return e.msg
+}
+
+// A ConflictError is an error that represents that a reservation could not be
+// updated because it was modified concurrently.
+type ConflictError struct {
+	msg string
+}
+
+func (e ConflictError) Error() string {
+	return e.msg
+}
+
+// A PreconditionFailedError is an error that represents that a reservation
+// does not have the version the client expected, because it was modified
+// since the client retrieved it.
+type PreconditionFailedError struct {
+	msg string
+}
+
+func (e PreconditionFailedError) Error() string {
+	return e.msg
+}
+
+// A BatchError is an error that represents that some of the reservations of a
+// batch failed. Errs contains the error of each reservation of the batch, in
+// order, with nil for those that did not fail.
+type BatchError struct {
+	msg  string
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	return e.msg
+}
+
+// A ForbiddenError is an error that represents that the authenticated user is
+// not allowed to perform an operation on a reservation, for example because
+// they are not the trip's driver.
+type ForbiddenError struct {
+	msg string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.msg
+}
@@ -2,12 +2,16 @@ package reservation
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"time"
 
+	"azure.com/ecovo/reservation-service/pkg/db"
 	"azure.com/ecovo/reservation-service/pkg/entity"
 	"github.com/mongodb/mongo-go-driver/bson"
 	"github.com/mongodb/mongo-go-driver/bson/primitive"
 	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/options"
 )
 
 const (
@@ -25,12 +29,50 @@ type MongoRepository struct {
 }
 
 type document struct {
-	ID            primitive.ObjectID `bson:"_id,omitempty"`
-	TripID        primitive.ObjectID `bson:"tripId"`
-	UserID        primitive.ObjectID `bson:"userId"`
-	SourceID      primitive.ObjectID `bson:"sourceId"`
-	DestinationID primitive.ObjectID `bson:"destinationId"`
-	Seats         int                `bson:"seats"`
+	ID            primitive.ObjectID    `bson:"_id,omitempty"`
+	TripID        primitive.ObjectID    `bson:"tripId"`
+	UserID        primitive.ObjectID    `bson:"userId"`
+	SourceID      primitive.ObjectID    `bson:"sourceId"`
+	DestinationID primitive.ObjectID    `bson:"destinationId"`
+	Seats         int                   `bson:"seats"`
+	Fare          *fareDocument         `bson:"fare,omitempty"`
+	Payment       *paymentDocument      `bson:"payment,omitempty"`
+	Status        string                `bson:"status"`
+	Active        bool                  `bson:"active"`
+	Version       int                   `bson:"version"`
+	Cancellation  *cancellationDocument `bson:"cancellation,omitempty"`
+	CheckIn       *checkInDocument      `bson:"checkIn,omitempty"`
+	CheckInCode   string                `bson:"checkInCode,omitempty"`
+}
+
+type fareDocument struct {
+	Amount   int    `bson:"amount"`
+	Currency string `bson:"currency"`
+}
+
+type paymentDocument struct {
+	AuthorizationID string `bson:"authorizationId"`
+	Status          string `bson:"status"`
+	Captured        int    `bson:"captured"`
+	Refunded        int    `bson:"refunded"`
+}
+
+type cancellationDocument struct {
+	Rule        string    `bson:"rule"`
+	Fee         int       `bson:"fee"`
+	Reason      string    `bson:"reason,omitempty"`
+	CancelledAt time.Time `bson:"cancelledAt"`
+}
+
+type checkInDocument struct {
+	Method      string         `bson:"method"`
+	Point       *pointDocument `bson:"point,omitempty"`
+	CheckedInAt time.Time      `bson:"checkedInAt"`
+}
+
+type pointDocument struct {
+	Latitude  float64 `bson:"latitude"`
+	Longitude float64 `bson:"longitude"`
 }
 
 func newDocumentFromEntity(r *entity.Reservation) (*document, error) {
@@ -63,25 +105,118 @@ func newDocumentFromEntity(r *entity.Reservation) (*document, error) {
 		return nil, err
 	}
 
-	return &document{
-		reservationID,
-		tripID,
-		driverID,
-		sourceID,
-		destinationID,
-		r.Seats,
-	}, nil
+	d := &document{
+		ID:            reservationID,
+		TripID:        tripID,
+		UserID:        driverID,
+		SourceID:      sourceID,
+		DestinationID: destinationID,
+		Seats:         r.Seats,
+		Status:        string(r.Status),
+		Active:        r.IsActive(),
+		Version:       r.Version,
+		CheckInCode:   r.CheckInCode,
+	}
+
+	if r.Fare != nil {
+		d.Fare = &fareDocument{
+			Amount:   r.Fare.Amount,
+			Currency: r.Fare.Currency,
+		}
+	}
+
+	if r.Payment != nil {
+		d.Payment = &paymentDocument{
+			AuthorizationID: r.Payment.AuthorizationID,
+			Status:          string(r.Payment.Status),
+			Captured:        r.Payment.Captured,
+			Refunded:        r.Payment.Refunded,
+		}
+	}
+
+	if r.Cancellation != nil {
+		d.Cancellation = &cancellationDocument{
+			Rule:        r.Cancellation.Rule,
+			Fee:         r.Cancellation.Fee,
+			Reason:      r.Cancellation.Reason,
+			CancelledAt: r.Cancellation.CancelledAt,
+		}
+	}
+
+	if r.CheckIn != nil {
+		d.CheckIn = &checkInDocument{
+			Method:      string(r.CheckIn.Method),
+			CheckedInAt: r.CheckIn.CheckedInAt,
+		}
+
+		if r.CheckIn.Point != nil {
+			d.CheckIn.Point = &pointDocument{
+				Latitude:  r.CheckIn.Point.Latitude,
+				Longitude: r.CheckIn.Point.Longitude,
+			}
+		}
+	}
+
+	return d, nil
 }
 
 func (d document) Entity() *entity.Reservation {
-	return &entity.Reservation{
-		entity.NewIDFromHex(d.ID.Hex()),
-		entity.NewIDFromHex(d.TripID.Hex()),
-		entity.NewIDFromHex(d.UserID.Hex()),
-		entity.NewIDFromHex(d.SourceID.Hex()),
-		entity.NewIDFromHex(d.DestinationID.Hex()),
-		d.Seats,
+	r := &entity.Reservation{
+		ID:            entity.NewIDFromHex(d.ID.Hex()),
+		TripID:        entity.NewIDFromHex(d.TripID.Hex()),
+		UserID:        entity.NewIDFromHex(d.UserID.Hex()),
+		SourceID:      entity.NewIDFromHex(d.SourceID.Hex()),
+		DestinationID: entity.NewIDFromHex(d.DestinationID.Hex()),
+		Seats:         d.Seats,
+		Status:        entity.Status(d.Status),
+		Version:       d.Version,
+		CheckInCode:   d.CheckInCode,
+	}
+
+	if r.Status == "" {
+		r.Status = entity.StatusConfirmed
+	}
+
+	if d.Fare != nil {
+		r.Fare = &entity.Fare{
+			Amount:   d.Fare.Amount,
+			Currency: d.Fare.Currency,
+		}
+	}
+
+	if d.Payment != nil {
+		r.Payment = &entity.Payment{
+			AuthorizationID: d.Payment.AuthorizationID,
+			Status:          entity.PaymentStatus(d.Payment.Status),
+			Captured:        d.Payment.Captured,
+			Refunded:        d.Payment.Refunded,
+		}
 	}
+
+	if d.Cancellation != nil {
+		r.Cancellation = &entity.Cancellation{
+			Rule:        d.Cancellation.Rule,
+			Fee:         d.Cancellation.Fee,
+			Reason:      d.Cancellation.Reason,
+			CancelledAt: d.Cancellation.CancelledAt,
+		}
+	}
+
+	if d.CheckIn != nil {
+		r.CheckIn = &entity.CheckIn{
+			Method:      entity.CheckInMethod(d.CheckIn.Method),
+			CheckedInAt: d.CheckIn.CheckedInAt,
+		}
+
+		if d.CheckIn.Point != nil {
+			r.CheckIn.Point = &entity.Point{
+				Latitude:  d.CheckIn.Point.Latitude,
+				Longitude: d.CheckIn.Point.Longitude,
+			}
+		}
+	}
+
+	return r
 }
 
 // NewMongoRepository creates a reservation repository for a MongoDB collection.
@@ -100,7 +235,7 @@ func (r *MongoRepository) FindByID(ID entity.ID) (*entity.Reservation, error) {
 		return nil, fmt.Errorf("reservation.MongoRepository: failed to create object ID")
 	}
 
-	filter := bson.D{{"_id", objectID}}
+	filter := bson.D{{Key: "_id", Value: objectID}}
 	var d document
 	err = r.collection.FindOne(context.TODO(), filter).Decode(&d)
 	if err != nil {
@@ -110,20 +245,201 @@ func (r *MongoRepository) FindByID(ID entity.ID) (*entity.Reservation, error) {
 	return d.Entity(), nil
 }
 
+// FindByTripID retrieves the reservations made on the trip with the given ID.
+func (r *MongoRepository) FindByTripID(tripID entity.ID) ([]*entity.Reservation, error) {
+	objectID, err := primitive.ObjectIDFromHex(tripID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "tripId", Value: objectID}}
+	return r.find(filter)
+}
+
+// Find retrieves the reservations selected by the criteria.
+func (r *MongoRepository) Find(c *Criteria) ([]*entity.Reservation, error) {
+	filter, err := criteriaFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.find(filter)
+}
+
+// FindEach calls fn with every reservation selected by the criteria, oldest
+// first, without loading them all in memory. It stops at the first error
+// returned by fn, and returns it.
+func (r *MongoRepository) FindEach(c *Criteria, fn func(res *entity.Reservation) error) error {
+	filter, err := criteriaFilter(c)
+	if err != nil {
+		return err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	return r.each(filter, opts, fn)
+}
+
+func criteriaFilter(c *Criteria) (bson.D, error) {
+	if c == nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: criteria are nil")
+	}
+
+	filter := bson.D{}
+	if !c.TripID.IsZero() {
+		objectID, err := primitive.ObjectIDFromHex(c.TripID.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("reservation.MongoRepository: failed to create object ID")
+		}
+		filter = append(filter, bson.E{Key: "tripId", Value: objectID})
+	}
+	if !c.UserID.IsZero() {
+		objectID, err := primitive.ObjectIDFromHex(c.UserID.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("reservation.MongoRepository: failed to create object ID")
+		}
+		filter = append(filter, bson.E{Key: "userId", Value: objectID})
+	}
+	if c.Status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: string(c.Status)})
+	}
+
+	// Reservations do not store when they were created, but their object IDs
+	// start with it, so a range of creation times is a range of IDs.
+	created := bson.D{}
+	if !c.CreatedFrom.IsZero() {
+		created = append(created, bson.E{Key: "$gte", Value: objectIDFromTime(c.CreatedFrom)})
+	}
+	if !c.CreatedTo.IsZero() {
+		created = append(created, bson.E{Key: "$lt", Value: objectIDFromTime(c.CreatedTo)})
+	}
+	if len(created) > 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: created})
+	}
+
+	return filter, nil
+}
+
+// objectIDFromTime returns the smallest object ID that can be generated at the
+// given time.
+func objectIDFromTime(t time.Time) primitive.ObjectID {
+	var ID primitive.ObjectID
+	binary.BigEndian.PutUint32(ID[:4], uint32(t.Unix()))
+
+	return ID
+}
+
+// FindTripIDs retrieves the IDs of the trips on which reservations were made.
+func (r *MongoRepository) FindTripIDs() ([]entity.ID, error) {
+	return r.distinctTripIDs(bson.D{})
+}
+
+// FindActiveTripIDs retrieves the IDs of the trips on which active
+// reservations were made.
+func (r *MongoRepository) FindActiveTripIDs() ([]entity.ID, error) {
+	filter := bson.D{{Key: "active", Value: true}}
+	return r.distinctTripIDs(filter)
+}
+
+// FindBookedBy retrieves the active reservations of the user with the given
+// ID, along with those they cancelled after the given time.
+func (r *MongoRepository) FindBookedBy(userID entity.ID, cancelledSince time.Time) ([]*entity.Reservation, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{
+		{Key: "userId", Value: objectID},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "active", Value: true}},
+			bson.D{
+				{Key: "status", Value: string(entity.StatusCancelled)},
+				{Key: "cancellation.cancelledAt", Value: bson.D{{Key: "$gt", Value: cancelledSince}}},
+			},
+		}},
+	}
+	return r.find(filter)
+}
+
+func (r *MongoRepository) distinctTripIDs(filter interface{}) ([]entity.ID, error) {
+	values, err := r.collection.Distinct(context.TODO(), "tripId", filter)
+	if err != nil {
+		return nil, fmt.Errorf("reservation.MongoRepository: failed to find trip IDs (%s)", err)
+	}
+
+	tripIDs := []entity.ID{}
+	for _, v := range values {
+		objectID, ok := v.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+
+		tripIDs = append(tripIDs, entity.NewIDFromHex(objectID.Hex()))
+	}
+
+	return tripIDs, nil
+}
+
+func (r *MongoRepository) find(filter interface{}) ([]*entity.Reservation, error) {
+	reservations := []*entity.Reservation{}
+	err := r.each(filter, nil, func(res *entity.Reservation) error {
+		reservations = append(reservations, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+func (r *MongoRepository) each(filter interface{}, opts *options.FindOptions, fn func(res *entity.Reservation) error) error {
+	cur, err := r.collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return fmt.Errorf("reservation.MongoRepository: failed to find reservations (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	for cur.Next(context.TODO()) {
+		var d document
+		err := cur.Decode(&d)
+		if err != nil {
+			return fmt.Errorf("reservation.MongoRepository: failed to decode reservation (%s)", err)
+		}
+
+		err = fn(d.Entity())
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("reservation.MongoRepository: failed to find reservations (%s)", err)
+	}
+
+	return nil
+}
+
 // Create stores the new reservation in the database and returns the unique
-// identifier that was generated for it.
+// identifier that was generated for it. The reservation's version is set to
+// its initial value.
 func (r *MongoRepository) Create(res *entity.Reservation) (entity.ID, error) {
 	if res == nil {
 		return entity.NilID, fmt.Errorf("reservation.MongoRepository: failed to create reservation (reservation is nil)")
 	}
 
+	res.Version = entity.InitialVersion
+
 	d, err := newDocumentFromEntity(res)
 	if err != nil {
 		return entity.NilID, fmt.Errorf("reservation.MongoRepository: failed to create reservation document from entity (%s)", err)
 	}
 
 	resp, err := r.collection.InsertOne(context.TODO(), d)
-	if err != nil {
+	if db.IsDuplicateKeyError(err) {
+		return entity.NilID, AlreadyExistsError{fmt.Sprintf("reservation.MongoRepository: user \"%s\" already has a reservation on trip \"%s\"", res.UserID, res.TripID)}
+	} else if err != nil {
 		return entity.NilID, fmt.Errorf("reservation.MongoRepository: failed to create reservation (%s)", err)
 	}
 
@@ -135,16 +451,24 @@ func (r *MongoRepository) Create(res *entity.Reservation) (entity.ID, error) {
 	return entity.ID(ID.Hex()), nil
 }
 
-// Update updates the reservation in the database.
+// Update updates the reservation in the database, as long as it was not
+// modified since it was retrieved, and increments its version.
+//
+// A ConflictError is returned when the reservation's version does not match
+// the stored one.
 func (r *MongoRepository) Update(res *entity.Reservation) error {
 	d, err := newDocumentFromEntity(res)
 	if err != nil {
 		return fmt.Errorf("reservation.MongoRepository: failed to create reservation document from entity (%s)", err)
 	}
+	d.Version = res.Version + 1
 
-	filter := bson.D{{"_id", d.ID}}
+	filter := bson.D{
+		{Key: "_id", Value: d.ID},
+		{Key: "version", Value: res.Version},
+	}
 	update := bson.D{
-		bson.E{"$set", d},
+		bson.E{Key: "$set", Value: d},
 	}
 	resp, err := r.collection.UpdateOne(context.TODO(), filter, update)
 	if err != nil {
@@ -152,9 +476,16 @@ func (r *MongoRepository) Update(res *entity.Reservation) error {
 	}
 
 	if resp.MatchedCount <= 0 {
-		return fmt.Errorf("reservation.MongoRepository: no matching reservation was found")
+		_, err := r.FindByID(res.ID)
+		if err != nil {
+			return fmt.Errorf("reservation.MongoRepository: no matching reservation was found")
+		}
+
+		return ConflictError{fmt.Sprintf("reservation.MongoRepository: reservation with ID \"%s\" was modified since version %d", res.ID, res.Version)}
 	}
 
+	res.Version = d.Version
+
 	return nil
 }
 
@@ -165,7 +496,7 @@ func (r *MongoRepository) Delete(ID entity.ID) error {
 		return fmt.Errorf("reservation.MongoRepository: failed to create object ID")
 	}
 
-	filter := bson.D{{"_id", objectID}}
+	filter := bson.D{{Key: "_id", Value: objectID}}
 	_, err = r.collection.DeleteOne(context.TODO(), filter)
 	if err != nil {
 		return fmt.Errorf("reservation.MongoRepository: failed to delete reservation with ID \"%s\" (%s)", ID, err)
@@ -0,0 +1,94 @@
+package reservation
+
+import (
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// authorizePayment places a hold on the reservation's fare. Reservations that
+// are free of charge are not authorized.
+func (s *Service) authorizePayment(r *entity.Reservation) (*entity.Payment, error) {
+	if r.Fare == nil || r.Fare.Amount <= 0 {
+		return nil, nil
+	}
+
+	authorizationID, err := s.paymentProvider.Authorize(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Payment{
+		AuthorizationID: authorizationID,
+		Status:          entity.PaymentAuthorized,
+	}, nil
+}
+
+// voidPayment releases the hold placed on the reservation's fare, if any.
+func (s *Service) voidPayment(r *entity.Reservation) error {
+	if r.Payment == nil || r.Payment.Status != entity.PaymentAuthorized {
+		return nil
+	}
+
+	err := s.paymentProvider.Void(r.Payment.AuthorizationID)
+	if err != nil {
+		return err
+	}
+
+	r.Payment.Status = entity.PaymentVoided
+
+	return nil
+}
+
+// capturePayment charges the whole fare of the reservation.
+func (s *Service) capturePayment(r *entity.Reservation) error {
+	err := s.paymentProvider.Capture(r.Payment.AuthorizationID, r.Fare.Amount)
+	if err != nil {
+		return err
+	}
+
+	r.Payment.Status = entity.PaymentCaptured
+	r.Payment.Captured = r.Fare.Amount
+
+	return nil
+}
+
+// settlePayment charges the cancellation fee of a cancelled reservation. An
+// authorized payment is captured for the amount of the fee, or voided when
+// there is no fee, while a captured payment is refunded everything but the
+// fee.
+func (s *Service) settlePayment(r *entity.Reservation) error {
+	if r.Payment == nil || r.Fare == nil || r.Cancellation == nil {
+		return nil
+	}
+
+	fee := r.Fare.Amount * r.Cancellation.Fee / 100
+
+	switch r.Payment.Status {
+	case entity.PaymentAuthorized:
+		if fee == 0 {
+			return s.voidPayment(r)
+		}
+
+		err := s.paymentProvider.Capture(r.Payment.AuthorizationID, fee)
+		if err != nil {
+			return err
+		}
+
+		r.Payment.Status = entity.PaymentCaptured
+		r.Payment.Captured = fee
+	case entity.PaymentCaptured:
+		refund := r.Payment.Captured - fee
+		if refund <= 0 {
+			return nil
+		}
+
+		err := s.paymentProvider.Refund(r.Payment.AuthorizationID, refund)
+		if err != nil {
+			return err
+		}
+
+		r.Payment.Status = entity.PaymentRefunded
+		r.Payment.Refunded = refund
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package reservation
 
 import (
+	"time"
+
 	"azure.com/ecovo/reservation-service/pkg/entity"
 )
 
@@ -8,7 +10,27 @@ import (
 // operations on reservations in a database.
 type Repository interface {
 	FindByID(ID entity.ID) (*entity.Reservation, error)
+	FindByTripID(tripID entity.ID) ([]*entity.Reservation, error)
+	Find(c *Criteria) ([]*entity.Reservation, error)
+	FindEach(c *Criteria, fn func(r *entity.Reservation) error) error
+	FindTripIDs() ([]entity.ID, error)
+	FindActiveTripIDs() ([]entity.ID, error)
+	FindBookedBy(userID entity.ID, cancelledSince time.Time) ([]*entity.Reservation, error)
 	Create(reservation *entity.Reservation) (entity.ID, error)
 	Update(reservation *entity.Reservation) error
 	Delete(ID entity.ID) error
 }
+
+// Criteria select reservations by the fields that are set. Empty criteria
+// select every reservation.
+type Criteria struct {
+	TripID entity.ID
+	UserID entity.ID
+	Status entity.Status
+
+	// CreatedFrom and CreatedTo select the reservations created at or after
+	// CreatedFrom and before CreatedTo, to the second. A zero time leaves
+	// that end of the range open.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
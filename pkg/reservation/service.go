@@ -1,68 +1,330 @@
 package reservation
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
+	"time"
 
+	"azure.com/ecovo/reservation-service/pkg/audit"
+	"azure.com/ecovo/reservation-service/pkg/booking"
+	"azure.com/ecovo/reservation-service/pkg/cancellation"
+	"azure.com/ecovo/reservation-service/pkg/checkin"
 	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/event"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+	"azure.com/ecovo/reservation-service/pkg/payment"
+	"azure.com/ecovo/reservation-service/pkg/pricing"
+	"azure.com/ecovo/reservation-service/pkg/requestid"
 	"azure.com/ecovo/reservation-service/pkg/trip"
 )
 
 // UseCase is an interface representing the ability to handle the business
 // logic that involves reservations.
 type UseCase interface {
-	Register(r *entity.Reservation) (*entity.Reservation, error)
-	FindByID(ID entity.ID) (*entity.Reservation, error)
-	Delete(ID entity.ID) error
+	Register(ctx context.Context, r *entity.Reservation) (*entity.Reservation, error)
+	Unregister(ctx context.Context, ID entity.ID) error
+	RegisterBatch(ctx context.Context, rs []*entity.Reservation) ([]*entity.Reservation, error)
+	FindByID(ctx context.Context, ID entity.ID) (*entity.Reservation, error)
+	FindForUser(ctx context.Context, ID entity.ID) (*entity.Reservation, error)
+	Delete(ctx context.Context, ID entity.ID, version int) (*entity.Reservation, error)
+	DeleteBatch(ctx context.Context, IDs []entity.ID) ([]*entity.Reservation, error)
+	CompleteTrip(ctx context.Context, tripID entity.ID) ([]*entity.Reservation, error)
+	CancelTrip(ctx context.Context, tripID entity.ID) ([]*entity.Reservation, error)
+
+	FindByTripID(ctx context.Context, tripID entity.ID) ([]*entity.Reservation, error)
+	Accept(ctx context.Context, tripID entity.ID, ID entity.ID, version int) (*entity.Reservation, error)
+	Reject(ctx context.Context, tripID entity.ID, ID entity.ID, reason string, version int) (*entity.Reservation, error)
+	CancelByDriver(ctx context.Context, tripID entity.ID, ID entity.ID, reason string, version int) (*entity.Reservation, error)
+
+	CheckIn(ctx context.Context, tripID entity.ID, ID entity.ID, code string, version int) (*entity.Reservation, error)
+	SelfCheckIn(ctx context.Context, ID entity.ID, point *entity.Point, version int) (*entity.Reservation, error)
+	MarkNoShows(ctx context.Context) ([]*entity.Reservation, error)
+
+	Watch(ctx context.Context, ID entity.ID) (*Subscription, error)
+	WatchUser(ctx context.Context) (*Subscription, error)
+	WatchTrip(ctx context.Context, tripID entity.ID) (*Subscription, error)
+
+	List(ctx context.Context, c *Criteria) ([]*entity.Reservation, error)
+	CancelAsPassenger(ctx context.Context, ID entity.ID) (*entity.Reservation, error)
+	ForceDelete(ctx context.Context, ID entity.ID) (*entity.Reservation, error)
+	Export(ctx context.Context, c *Criteria, format Format, w io.Writer) (int, error)
+	Import(ctx context.Context, format Format, r io.Reader, dryRun bool) (*ImportReport, error)
 }
 
+// TripCancelledReason is the reason recorded on the reservations that are
+// cancelled because their trip was cancelled.
+const TripCancelledReason = "trip was cancelled by the driver"
+
+// MaximumBatchSize represents the maximum number of reservations that can be
+// registered or cancelled at once.
+const MaximumBatchSize = 20
+
 // A Service handles the business logic related to reservations.
 type Service struct {
-	repo        Repository
-	tripService trip.UseCase
+	repo               Repository
+	tripService        trip.UseCase
+	pricingService     pricing.UseCase
+	holdService        hold.UseCase
+	paymentProvider    payment.Provider
+	cancellationPolicy *cancellation.Policy
+	checkInPolicy      *checkin.Policy
+	bookingPolicy      *booking.Policy
+	publisher          event.Publisher
+	auditService       audit.UseCase
+	bus                *Bus
 }
 
 // NewService creates a reservation service to handle business logic and manipulate
 // reservations through a repository.
-func NewService(repo Repository, tripService trip.UseCase) *Service {
-	return &Service{repo, tripService}
+//
+// The pricing service is used to compute the fare of a reservation when it is
+// booked, the hold service is used to book the seats that were held, the
+// payment provider is used to charge the fare, the cancellation policy is used
+// to determine the fee charged when a reservation is cancelled, and the
+// check-in policy is used to determine when passengers can check in and when
+// they are no-shows, and the booking policy is used to limit the reservations
+// a passenger can make. Changes to reservations are published as events through
+// the publisher, notified to the subscribers of the service's bus and recorded
+// in the audit log through the audit service.
+func NewService(repo Repository, tripService trip.UseCase, pricingService pricing.UseCase, holdService hold.UseCase, paymentProvider payment.Provider, cancellationPolicy *cancellation.Policy, checkInPolicy *checkin.Policy, bookingPolicy *booking.Policy, publisher event.Publisher, auditService audit.UseCase) *Service {
+	return &Service{repo, tripService, pricingService, holdService, paymentProvider, cancellationPolicy, checkInPolicy, bookingPolicy, publisher, auditService, NewBus()}
 }
 
 // Register modifies reservation repository based on a reservation done.
-func (s *Service) Register(r *entity.Reservation) (*entity.Reservation, error) {
+//
+// Booking a reservation is done in steps: the fare is authorized with the
+// payment provider, the reservation is stored, and it is registered on the
+// trip. When a step fails, the steps that were completed before it are
+// compensated for.
+//
+// When the reservation has a hold ID, the hold is consumed instead of
+// registering the reservation on the trip, since its seats are already held
+// there. The reservation takes the hold's ID.
+func (s *Service) Register(ctx context.Context, r *entity.Reservation) (*entity.Reservation, error) {
+	err := s.prepare(ctx, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.book(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, audit.OperationCreate, nil, r)
+	s.publish(ctx, event.ReservationCreated, r)
+
+	return r, nil
+}
+
+// RegisterBatch registers several reservations at once, all or nothing.
+//
+// Every reservation is validated and priced before any of them is booked.
+// When a reservation fails to be booked, the reservations of the batch that
+// were already booked are rolled back. In both cases, a BatchError reports
+// which reservations failed.
+func (s *Service) RegisterBatch(ctx context.Context, rs []*entity.Reservation) ([]*entity.Reservation, error) {
+	if len(rs) == 0 {
+		return nil, entity.NewFieldValidationError("reservations", "must not be empty")
+	}
+
+	if len(rs) > MaximumBatchSize {
+		return nil, entity.NewFieldValidationError("reservations", fmt.Sprintf("cannot contain more than %d reservations", MaximumBatchSize))
+	}
+
+	errs := make([]error, len(rs))
+	failed := false
+	for i, r := range rs {
+		errs[i] = s.prepare(ctx, r, rs[:i])
+		if errs[i] != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		return nil, BatchError{"reservation.Service: batch is invalid", errs}
+	}
+
+	for i, r := range rs {
+		err := s.book(r)
+		if err != nil {
+			errs[i] = err
+
+			for _, booked := range rs[:i] {
+				rbErr := s.rollback(booked)
+				if rbErr != nil {
+					return nil, rbErr
+				}
+			}
+
+			return nil, BatchError{"reservation.Service: failed to book batch", errs}
+		}
+	}
+
+	for _, r := range rs {
+		s.record(ctx, audit.OperationCreate, nil, r)
+		s.publish(ctx, event.ReservationCreated, r)
+	}
+
+	return rs, nil
+}
+
+// prepare validates and prices a reservation before it is booked, once the
+// booking policy allows it. A reservation can only be booked for the
+// authenticated user. The reservations of the same batch that are
+// prepared before it are given, since they are booked along with it.
+func (s *Service) prepare(ctx context.Context, r *entity.Reservation, batch []*entity.Reservation) error {
 	if r == nil {
-		return nil, fmt.Errorf("reservation.Service: reservation is nil")
+		return fmt.Errorf("reservation.Service: reservation is nil")
 	}
 
-	_, err := s.FindByID(r.ID)
+	_, err := s.FindByID(ctx, r.ID)
 	if err == nil {
-		return nil, AlreadyExistsError{fmt.Sprintf("reservation.Service: reservation already exists with ID \"%s\"", r.ID)}
+		return AlreadyExistsError{fmt.Sprintf("reservation.Service: reservation already exists with ID \"%s\"", r.ID)}
 	}
 
 	err = r.Validate()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	r.ID, err = s.repo.Create(r)
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil || userInfo.SubID == "" || userInfo.SubID != r.UserID.Hex() {
+		return ForbiddenError{fmt.Sprintf("reservation.Service: reservations can only be booked for the authenticated user, not for user \"%s\"", r.UserID)}
+	}
+
+	err = s.enforceBookingPolicy(r, batch)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = s.tripService.RegisterReservation(r)
+	r.Fare, err = s.pricingService.Quote(r)
 	if err != nil {
-		delErr := s.repo.Delete(r.ID)
-		if delErr != nil {
-			return nil, delErr
+		return err
+	}
+
+	r.Status = entity.StatusPending
+	r.Cancellation = nil
+	r.CheckIn = nil
+
+	r.CheckInCode, err = newCheckInCode()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// book authorizes the payment of a prepared reservation, stores it and
+// registers it on the trip, or consumes its hold.
+func (s *Service) book(r *entity.Reservation) error {
+	var err error
+	r.Payment, err = s.authorizePayment(r)
+	if err != nil {
+		return err
+	}
+
+	held := !r.HoldID.IsZero()
+	if held {
+		h, err := s.holdService.Consume(r.HoldID, r)
+		if err != nil {
+			voidErr := s.voidPayment(r)
+			if voidErr != nil {
+				return voidErr
+			}
+			return err
 		}
-		return nil, err
+
+		r.ID = h.ID
 	}
 
-	return r, nil
+	// The reservation keeps the hold's ID until it is stored, so that the
+	// seats of a consumed hold, which are registered on the trip under that
+	// ID, can be released if it cannot be.
+	ID, err := s.repo.Create(r)
+	if err != nil {
+		if held {
+			relErr := s.tripService.DeleteReservation(r)
+			if relErr != nil {
+				return relErr
+			}
+		}
+		voidErr := s.voidPayment(r)
+		if voidErr != nil {
+			return voidErr
+		}
+		return err
+	}
+	r.ID = ID
+
+	if !held {
+		err = s.tripService.RegisterReservation(r)
+		if err != nil {
+			delErr := s.repo.Delete(r.ID)
+			if delErr != nil {
+				return delErr
+			}
+			voidErr := s.voidPayment(r)
+			if voidErr != nil {
+				return voidErr
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollback undoes the booking of a reservation, as if it never happened: it
+// is removed from the trip and the repository, and its payment is voided.
+func (s *Service) rollback(r *entity.Reservation) error {
+	err := s.tripService.DeleteReservation(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(r.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.voidPayment(r)
+}
+
+// Unregister undoes the registration of a reservation whose passenger could not
+// be told it was booked, as if it never happened: it is removed from its trip
+// and the repository, and its payment is voided, without applying the
+// cancellation policy. Only the passenger of the reservation can unregister it.
+func (s *Service) Unregister(ctx context.Context, ID entity.ID) error {
+	res, err := s.FindByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	err = AuthorizePassenger(ctx, res)
+	if err != nil {
+		return err
+	}
+
+	before := res.Clone()
+
+	err = s.rollback(res)
+	if err != nil {
+		return err
+	}
+
+	s.record(ctx, audit.OperationRollback, before, nil)
+	s.publish(ctx, event.ReservationCancelled, res)
+
+	return nil
 }
 
 // FindByID retrieves the reservation with the given ID in the repository, if it
 // exists.
-func (s *Service) FindByID(ID entity.ID) (*entity.Reservation, error) {
+func (s *Service) FindByID(ctx context.Context, ID entity.ID) (*entity.Reservation, error) {
 	r, err := s.repo.FindByID(ID)
 	if err != nil {
 		return nil, NotFoundError{err.Error()}
@@ -71,22 +333,348 @@ func (s *Service) FindByID(ID entity.ID) (*entity.Reservation, error) {
 	return r, nil
 }
 
-// Delete modifies reservation respository based on a reservation done.
-func (s *Service) Delete(ID entity.ID) error {
-	res, err := s.repo.FindByID(ID)
+// checkVersion ensures that the reservation has the given version, unless it
+// is zero. Since a reservation is only updated as long as it still has the
+// version it was retrieved with, it cannot be modified in between.
+func checkVersion(res *entity.Reservation, version int) error {
+	if version == 0 || version == res.Version {
+		return nil
+	}
+
+	return PreconditionFailedError{fmt.Sprintf("reservation.Service: reservation with ID \"%s\" does not match version %d (current version is %d)", res.ID, version, res.Version)}
+}
+
+// FindForUser retrieves the reservation with the given ID, as long as the
+// authenticated user is its passenger or the driver of its trip.
+func (s *Service) FindForUser(ctx context.Context, ID entity.ID) (*entity.Reservation, error) {
+	res, err := s.FindByID(ctx, ID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = s.authorizeParticipant(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Delete cancels the reservation with the given ID and releases its seats
+// on the trip. The cancellation policy is evaluated against the trip's
+// departure time from the reservation's source, and the rule that was applied
+// along with the resulting fee are recorded on the cancelled reservation.
+//
+// The payment is settled according to the fee: the fee is charged and the rest
+// of the fare is released or refunded. If the payment cannot be settled, the
+// reservation is registered on the trip again.
+//
+// Only the passenger of the reservation can cancel it. When the version is not
+// zero, the reservation is only cancelled if it still has this version.
+func (s *Service) Delete(ctx context.Context, ID entity.ID, version int) (*entity.Reservation, error) {
+	res, err := s.FindByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = AuthorizePassenger(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cancelAsPassenger(ctx, res, version)
+}
+
+// cancelAsPassenger cancels an active reservation on behalf of its passenger,
+// applying the cancellation policy, as long as it has the given version, unless
+// it is zero.
+func (s *Service) cancelAsPassenger(ctx context.Context, res *entity.Reservation, version int) (*entity.Reservation, error) {
+	err := checkVersion(res, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.IsActive() {
+		return nil, InvalidStatusError{fmt.Sprintf("reservation.Service: reservation with ID \"%s\" is already %s", res.ID, res.Status)}
+	}
+
+	t, err := s.tripService.FindByID(res.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.cancellationPolicy.Evaluate(t.DepartureFrom(res.SourceID), time.Now())
+
+	return s.cancel(ctx, res, entity.StatusCancelled, c, audit.OperationCancel)
+}
+
+// cancel releases the seats of an active reservation on its trip, settles its
+// payment according to the given cancellation and stores it with the given
+// status.
+//
+// The cancellation is stored first, so that a concurrent modification of the
+// reservation fails it before the seats are released or the payment settled.
+// When a later step fails, the steps that were completed before it are
+// compensated for, and the reservation is restored.
+func (s *Service) cancel(ctx context.Context, res *entity.Reservation, status entity.Status, c *entity.Cancellation, operation string) (*entity.Reservation, error) {
+	before := res.Clone()
+
+	res.Status = status
+	res.Cancellation = c
+
+	err := s.repo.Update(res)
+	if err != nil {
+		return nil, err
 	}
 
 	err = s.tripService.DeleteReservation(res)
+	if err != nil {
+		restoreErr := s.restore(res, before)
+		if restoreErr != nil {
+			return nil, restoreErr
+		}
+		return nil, err
+	}
+
+	err = s.settlePayment(res)
+	if err != nil {
+		regErr := s.tripService.RegisterReservation(res)
+		if regErr != nil {
+			return nil, regErr
+		}
+		restoreErr := s.restore(res, before)
+		if restoreErr != nil {
+			return nil, restoreErr
+		}
+		return nil, err
+	}
+
+	err = s.repo.Update(res)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, operation, before, res)
+	s.publish(ctx, event.ReservationCancelled, res)
+
+	return res, nil
+}
+
+// restore stores a reservation as it was before an operation that failed
+// midway, over the version the operation stored.
+func (s *Service) restore(res *entity.Reservation, before *entity.Reservation) error {
+	restored := before.Clone()
+	restored.Version = res.Version
+
+	return s.repo.Update(restored)
+}
+
+// DeleteBatch cancels several reservations at once.
+//
+// Every reservation must exist, belong to the authenticated user and not be
+// cancelled yet before any of them is cancelled. Since a cancellation cannot be
+// undone without consequences, the cancellations that succeed are kept even if
+// others fail, in which case a BatchError reports which reservations failed. The reservations that were
+// cancelled are returned, with nil in place of those that failed.
+func (s *Service) DeleteBatch(ctx context.Context, IDs []entity.ID) ([]*entity.Reservation, error) {
+	if len(IDs) == 0 {
+		return nil, entity.NewFieldValidationError("ids", "must not be empty")
+	}
+
+	if len(IDs) > MaximumBatchSize {
+		return nil, entity.NewFieldValidationError("ids", fmt.Sprintf("cannot contain more than %d reservations", MaximumBatchSize))
+	}
+
+	errs := make([]error, len(IDs))
+	failed := false
+	for i, ID := range IDs {
+		res, err := s.FindByID(ctx, ID)
+		if err == nil {
+			err = AuthorizePassenger(ctx, res)
+		}
+		if err == nil && !res.IsActive() {
+			err = InvalidStatusError{fmt.Sprintf("reservation.Service: reservation with ID \"%s\" is already %s", ID, res.Status)}
+		}
+
+		errs[i] = err
+		if err != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		return nil, BatchError{"reservation.Service: batch is invalid", errs}
+	}
+
+	cancelled := make([]*entity.Reservation, len(IDs))
+	for i, ID := range IDs {
+		cancelled[i], errs[i] = s.Delete(ctx, ID, 0)
+		if errs[i] != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		return cancelled, BatchError{"reservation.Service: failed to cancel part of batch", errs}
+	}
+
+	return cancelled, nil
+}
+
+// CompleteTrip completes every confirmed reservation on the trip with the given
+// ID, once the trip has been completed, captures their payment and returns
+// them. Completed reservations no longer count as active bookings of their
+// passenger.
+//
+// The pending reservations, which the driver never accepted, expire and their
+// payment is voided, so that the passengers are not left with a hold on their
+// fare.
+func (s *Service) CompleteTrip(ctx context.Context, tripID entity.ID) ([]*entity.Reservation, error) {
+	reservations, err := s.repo.FindByTripID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := []*entity.Reservation{}
+	for _, res := range reservations {
+		if res.Status == entity.StatusPending {
+			err = s.expire(ctx, res)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if res.Status != entity.StatusConfirmed {
+			continue
+		}
+
+		before := res.Clone()
+
+		res.Status = entity.StatusCompleted
+
+		if res.Payment != nil && res.Payment.Status == entity.PaymentAuthorized {
+			err = s.capturePayment(res)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.repo.Update(res)
+		if err != nil {
+			return nil, err
+		}
+
+		s.record(ctx, audit.OperationComplete, before, res)
+		s.publish(ctx, event.ReservationModified, res)
+
+		completed = append(completed, res)
+	}
+
+	return completed, nil
+}
+
+// expire marks a pending reservation as expired and voids its payment. Its seats
+// are not released on the trip, since the trip was completed.
+func (s *Service) expire(ctx context.Context, res *entity.Reservation) error {
+	before := res.Clone()
+
+	res.Status = entity.StatusExpired
+
+	err := s.voidPayment(res)
 	if err != nil {
 		return err
 	}
 
-	err = s.repo.Delete(ID)
+	err = s.repo.Update(res)
 	if err != nil {
 		return err
 	}
 
+	s.record(ctx, audit.OperationExpire, before, res)
+	s.publish(ctx, event.ReservationCancelled, res)
+
 	return nil
 }
+
+// CancelTrip cancels every active reservation on the trip with the given ID,
+// once the trip has been cancelled by its driver, and returns the reservations
+// that were cancelled. The passengers are not charged.
+//
+// The seats are not released on the trip, since it no longer exists. The
+// reservations that were already cancelled are skipped, so that the request
+// can be retried safely after a failure.
+func (s *Service) CancelTrip(ctx context.Context, tripID entity.ID) ([]*entity.Reservation, error) {
+	reservations, err := s.repo.FindByTripID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled := []*entity.Reservation{}
+	for _, res := range reservations {
+		if !res.IsActive() {
+			continue
+		}
+
+		before := res.Clone()
+
+		res.Status = entity.StatusCancelledByDriver
+		res.Cancellation = &entity.Cancellation{
+			Rule:        cancellation.RuleTripCancelled,
+			Reason:      TripCancelledReason,
+			CancelledAt: time.Now(),
+		}
+
+		err = s.settlePayment(res)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.repo.Update(res)
+		if err != nil {
+			return nil, err
+		}
+
+		s.record(ctx, audit.OperationCancelTrip, before, res)
+		s.publish(ctx, event.ReservationCancelled, res)
+
+		cancelled = append(cancelled, res)
+	}
+
+	return cancelled, nil
+}
+
+// publish emits an event of the given type about the reservation, and notifies
+// the subscribers of the bus. Failing to publish an event does not fail the
+// operation that caused it, since the change was already made, so the error is
+// only logged.
+func (s *Service) publish(ctx context.Context, t event.Type, r *entity.Reservation) {
+	s.bus.Notify(&Change{t, r.Clone()})
+
+	e, err := event.New(ctx, t, r.ID.Hex(), r)
+	if err == nil {
+		err = s.publisher.Publish(e)
+	}
+
+	if err != nil {
+		requestID, _ := requestid.FromContext(ctx)
+		log.Printf("[Request ID=%s] reservation.Service: failed to publish %s event for reservation \"%s\" (%s)", requestID, t, r.ID, err)
+	}
+}
+
+// record appends an entry for an operation that changed the reservation to the
+// audit log. Like events, failing to record an entry does not fail the
+// operation, so the error is only logged.
+func (s *Service) record(ctx context.Context, operation string, before *entity.Reservation, after *entity.Reservation) {
+	err := s.auditService.Record(ctx, operation, before, after)
+	if err != nil {
+		res := after
+		if res == nil {
+			res = before
+		}
+
+		requestID, _ := requestid.FromContext(ctx)
+		log.Printf("[Request ID=%s] reservation.Service: failed to record %s of reservation \"%s\" in audit log (%s)", requestID, operation, res.ID, err)
+	}
+}
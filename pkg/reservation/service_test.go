@@ -0,0 +1,633 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/booking"
+	"azure.com/ecovo/reservation-service/pkg/cancellation"
+	"azure.com/ecovo/reservation-service/pkg/checkin"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/event"
+	"azure.com/ecovo/reservation-service/pkg/hold"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+	"azure.com/ecovo/reservation-service/pkg/payment"
+	"azure.com/ecovo/reservation-service/pkg/pricing"
+	"azure.com/ecovo/reservation-service/pkg/trip"
+)
+
+// A memoryRepository is a repository that keeps reservations in memory.
+type memoryRepository struct {
+	mu           sync.Mutex
+	reservations map[entity.ID]*entity.Reservation
+	nextID       int
+
+	// failCreate makes Create fail when it is set.
+	failCreate bool
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{reservations: make(map[entity.ID]*entity.Reservation)}
+}
+
+func (r *memoryRepository) FindByID(ID entity.ID) (*entity.Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, ok := r.reservations[ID]
+	if !ok {
+		return nil, fmt.Errorf("no reservation found with ID \"%s\"", ID)
+	}
+
+	return res.Clone(), nil
+}
+
+func (r *memoryRepository) FindByTripID(tripID entity.ID) ([]*entity.Reservation, error) {
+	return r.Find(&Criteria{TripID: tripID})
+}
+
+func (r *memoryRepository) Find(c *Criteria) ([]*entity.Reservation, error) {
+	reservations := []*entity.Reservation{}
+	err := r.FindEach(c, func(res *entity.Reservation) error {
+		reservations = append(reservations, res)
+		return nil
+	})
+
+	return reservations, err
+}
+
+func (r *memoryRepository) FindEach(c *Criteria, fn func(res *entity.Reservation) error) error {
+	r.mu.Lock()
+	selected := []*entity.Reservation{}
+	for _, res := range r.reservations {
+		if (c.TripID.IsZero() || res.TripID == c.TripID) && (c.UserID.IsZero() || res.UserID == c.UserID) && (c.Status == "" || res.Status == c.Status) {
+			selected = append(selected, res.Clone())
+		}
+	}
+	r.mu.Unlock()
+
+	for _, res := range selected {
+		err := fn(res)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryRepository) FindTripIDs() ([]entity.ID, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *memoryRepository) FindActiveTripIDs() ([]entity.ID, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *memoryRepository) FindBookedBy(userID entity.ID, cancelledSince time.Time) ([]*entity.Reservation, error) {
+	reservations, err := r.Find(&Criteria{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	booked := []*entity.Reservation{}
+	for _, res := range reservations {
+		if res.IsActive() || (res.Status == entity.StatusCancelled && res.Cancellation.CancelledAt.After(cancelledSince)) {
+			booked = append(booked, res)
+		}
+	}
+
+	return booked, nil
+}
+
+func (r *memoryRepository) Create(res *entity.Reservation) (entity.ID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failCreate {
+		return entity.NilID, errors.New("failed to create reservation")
+	}
+
+	for _, other := range r.reservations {
+		if other.UserID == res.UserID && other.TripID == res.TripID && other.IsActive() {
+			return entity.NilID, AlreadyExistsError{"user already has a reservation on trip"}
+		}
+	}
+
+	ID := res.ID
+	if ID.IsZero() {
+		r.nextID++
+		ID = entity.ID(fmt.Sprintf("reservation-%d", r.nextID))
+	}
+
+	res.Version = entity.InitialVersion
+	stored := res.Clone()
+	stored.ID = ID
+	r.reservations[ID] = stored
+
+	return ID, nil
+}
+
+func (r *memoryRepository) Update(res *entity.Reservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.reservations[res.ID]
+	if !ok {
+		return errors.New("no matching reservation was found")
+	}
+
+	if stored.Version != res.Version {
+		return ConflictError{fmt.Sprintf("reservation with ID \"%s\" was modified since version %d", res.ID, res.Version)}
+	}
+
+	res.Version++
+	r.reservations[res.ID] = res.Clone()
+
+	return nil
+}
+
+func (r *memoryRepository) Delete(ID entity.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.reservations, ID)
+
+	return nil
+}
+
+// A fakeTripService is a trip service that keeps the reservations registered
+// on its trips in memory.
+type fakeTripService struct {
+	trips      map[entity.ID]*entity.Trip
+	registered map[entity.ID]bool
+
+	// failRegister and failDelete make RegisterReservation and
+	// DeleteReservation fail when they are set.
+	failRegister bool
+	failDelete   bool
+}
+
+func (s *fakeTripService) FindByID(ID entity.ID) (*entity.Trip, error) {
+	t, ok := s.trips[ID]
+	if !ok {
+		return nil, trip.NotFoundError{}
+	}
+
+	return t, nil
+}
+
+func (s *fakeTripService) FindReservations(tripID entity.ID) ([]*entity.Reservation, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeTripService) RegisterReservation(r *entity.Reservation) error {
+	if s.failRegister {
+		return errors.New("failed to register reservation")
+	}
+
+	s.registered[r.ID] = true
+
+	return nil
+}
+
+func (s *fakeTripService) DeleteReservation(r *entity.Reservation) error {
+	if s.failDelete {
+		return errors.New("failed to delete reservation")
+	}
+
+	delete(s.registered, r.ID)
+
+	return nil
+}
+
+// A nopAuditService is an audit service that records nothing.
+type nopAuditService struct{}
+
+func (s nopAuditService) Record(ctx context.Context, operation string, before *entity.Reservation, after *entity.Reservation) error {
+	return nil
+}
+
+func (s nopAuditService) FindByReservationID(reservationID entity.ID) ([]*entity.AuditEntry, error) {
+	return nil, nil
+}
+
+// A noHoldService is a hold service for tests that book without holds.
+type noHoldService struct {
+	hold.UseCase
+}
+
+type fixture struct {
+	service   *Service
+	repo      *memoryRepository
+	trips     *fakeTripService
+	payments  *payment.FakeProvider
+	publisher *event.MemoryPublisher
+}
+
+func newFixture(t *testing.T, departure time.Time, bookingConf *booking.Config) *fixture {
+	trips := &fakeTripService{
+		trips: map[entity.ID]*entity.Trip{
+			"trip": {
+				ID:           "trip",
+				DriverID:     "driver",
+				LeaveAt:      departure,
+				ArriveBy:     departure.Add(time.Hour),
+				Seats:        4,
+				PricePerSeat: 1000,
+				Stops:        []*entity.Stop{{ID: "source"}, {ID: "destination"}},
+			},
+		},
+		registered: map[entity.ID]bool{},
+	}
+
+	cancellationPolicy, err := cancellation.NewPolicy(&cancellation.Config{FreePeriod: 24 * time.Hour, LateFee: 50, NoShowFee: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkInPolicy, err := checkin.NewPolicy(&checkin.Config{Radius: checkin.DefaultRadius, OpensBefore: checkin.DefaultOpensBefore, GracePeriod: checkin.DefaultGracePeriod})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bookingConf == nil {
+		bookingConf = &booking.Config{}
+	}
+	bookingPolicy, err := booking.NewPolicy(bookingConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fixture{
+		repo:      newMemoryRepository(),
+		trips:     trips,
+		payments:  payment.NewFakeProvider(),
+		publisher: event.NewMemoryPublisher(),
+	}
+	f.service = NewService(f.repo, trips, pricing.NewService(trips), noHoldService{}, f.payments, cancellationPolicy, checkInPolicy, bookingPolicy, f.publisher, nopAuditService{})
+
+	return f
+}
+
+// passengerContext returns a context in which the passenger of the
+// reservations created by newReservation is authenticated.
+func passengerContext() context.Context {
+	return context.WithValue(context.Background(), identity.UserInfoContextKey, &identity.UserInfo{SubID: "passenger"})
+}
+
+func newReservation() *entity.Reservation {
+	return &entity.Reservation{TripID: "trip", UserID: "passenger", SourceID: "source", DestinationID: "destination", Seats: 2}
+}
+
+func TestRegisterAuthorizesPayment(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+
+	r, err := f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Status != entity.StatusPending || r.Fare.Amount != 2000 {
+		t.Errorf("got status %s and fare %d, want pending and 2000", r.Status, r.Fare.Amount)
+	}
+
+	a, ok := f.payments.Authorization(r.Payment.AuthorizationID)
+	if !ok || a.Amount != 2000 || a.Voided || r.Payment.Status != entity.PaymentAuthorized {
+		t.Errorf("got authorization %+v with status %s, want 2000 authorized", a, r.Payment.Status)
+	}
+
+	if !f.trips.registered[r.ID] {
+		t.Errorf("reservation was not registered on the trip")
+	}
+
+	if _, err := f.repo.FindByID(r.ID); err != nil {
+		t.Errorf("reservation was not stored (%s)", err)
+	}
+
+	if len(f.publisher.Events()) != 1 {
+		t.Errorf("got %d events, want 1", len(f.publisher.Events()))
+	}
+}
+
+func TestRegisterStoresNothingWhenPaymentIsDeclined(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+	f.payments.Decline = true
+
+	_, err := f.service.Register(passengerContext(), newReservation())
+	if _, ok := err.(payment.DeclinedError); !ok {
+		t.Fatalf("got error %v, want a DeclinedError", err)
+	}
+
+	if len(f.repo.reservations) != 0 || len(f.trips.registered) != 0 || len(f.publisher.Events()) != 0 {
+		t.Errorf("declined reservation was booked")
+	}
+}
+
+func TestRegisterVoidsPaymentWhenStoringFails(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+	f.repo.failCreate = true
+
+	r := newReservation()
+	_, err := f.service.Register(passengerContext(), r)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	a, _ := f.payments.Authorization(r.Payment.AuthorizationID)
+	if !a.Voided || r.Payment.Status != entity.PaymentVoided {
+		t.Errorf("payment was not voided")
+	}
+
+	if len(f.trips.registered) != 0 {
+		t.Errorf("reservation was registered on the trip")
+	}
+}
+
+func TestRegisterCompensatesWhenTripRegistrationFails(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+	f.trips.failRegister = true
+
+	r := newReservation()
+	_, err := f.service.Register(passengerContext(), r)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(f.repo.reservations) != 0 {
+		t.Errorf("reservation was not removed from the repository")
+	}
+
+	a, _ := f.payments.Authorization(r.Payment.AuthorizationID)
+	if !a.Voided {
+		t.Errorf("payment was not voided")
+	}
+
+	if len(f.publisher.Events()) != 0 {
+		t.Errorf("got %d events, want none", len(f.publisher.Events()))
+	}
+}
+
+func TestRegisterBatchRollsBackBookedReservations(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+
+	// The second reservation is on the same trip as the first one, so it
+	// cannot be stored once the first one is.
+	first := newReservation()
+	second := newReservation()
+
+	_, err := f.service.RegisterBatch(passengerContext(), []*entity.Reservation{first, second})
+	if _, ok := err.(BatchError); !ok {
+		t.Fatalf("got error %v, want a BatchError", err)
+	}
+
+	if _, err := f.repo.FindByID(first.ID); err == nil {
+		t.Errorf("first reservation was not rolled back")
+	}
+
+	if len(f.trips.registered) != 0 {
+		t.Errorf("first reservation was not removed from the trip")
+	}
+
+	a, _ := f.payments.Authorization(first.Payment.AuthorizationID)
+	if !a.Voided {
+		t.Errorf("payment of first reservation was not voided")
+	}
+}
+
+func TestRegisterEnforcesBookingPolicy(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), &booking.Config{MaxActive: 1})
+	f.repo.reservations["active"] = &entity.Reservation{ID: "active", TripID: "other-trip", UserID: "passenger", Status: entity.StatusConfirmed}
+
+	_, err := f.service.Register(passengerContext(), newReservation())
+	if _, ok := err.(booking.TooManyActiveError); !ok {
+		t.Errorf("got error %v, want a TooManyActiveError", err)
+	}
+}
+
+func TestDeleteSettlesPaymentAccordingToPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		departure time.Duration
+		rule      string
+		captured  int
+		voided    bool
+	}{
+		{"free", 48 * time.Hour, cancellation.RuleFree, 0, true},
+		{"late", time.Hour, cancellation.RuleLate, 1000, false},
+	}
+
+	for _, tt := range tests {
+		f := newFixture(t, time.Now().Add(tt.departure), nil)
+
+		r, err := f.service.Register(passengerContext(), newReservation())
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		res, err := f.service.Delete(passengerContext(), r.ID, r.Version)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		if res.Status != entity.StatusCancelled || res.Cancellation.Rule != tt.rule {
+			t.Errorf("%s: got status %s and rule %s", tt.name, res.Status, res.Cancellation.Rule)
+		}
+
+		a, _ := f.payments.Authorization(r.Payment.AuthorizationID)
+		if a.Captured != tt.captured || a.Voided != tt.voided {
+			t.Errorf("%s: got authorization %+v, want %d captured and voided %t", tt.name, a, tt.captured, tt.voided)
+		}
+
+		if f.trips.registered[r.ID] {
+			t.Errorf("%s: reservation is still registered on the trip", tt.name)
+		}
+	}
+}
+
+func TestDeleteRestoresReservationWhenTripDeletionFails(t *testing.T) {
+	f := newFixture(t, time.Now().Add(time.Hour), nil)
+
+	r, err := f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.trips.failDelete = true
+	_, err = f.service.Delete(passengerContext(), r.ID, 0)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	stored, err := f.repo.FindByID(r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.Status != entity.StatusPending || stored.Cancellation != nil {
+		t.Errorf("got status %s, want pending without cancellation", stored.Status)
+	}
+
+	a, _ := f.payments.Authorization(r.Payment.AuthorizationID)
+	if a.Captured != 0 || a.Voided {
+		t.Errorf("payment was settled: %+v", a)
+	}
+}
+
+func TestDeleteChecksVersion(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+
+	r, err := f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.service.Delete(passengerContext(), r.ID, r.Version+1)
+	if _, ok := err.(PreconditionFailedError); !ok {
+		t.Errorf("got error %v, want a PreconditionFailedError", err)
+	}
+}
+
+func TestDeleteIsOnlyAllowedToPassenger(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+
+	r, err := f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), identity.UserInfoContextKey, &identity.UserInfo{SubID: "someone-else"})
+	_, err = f.service.Delete(ctx, r.ID, 0)
+	if _, ok := err.(ForbiddenError); !ok {
+		t.Errorf("got error %v, want a ForbiddenError", err)
+	}
+
+	stored, err := f.repo.FindByID(r.ID)
+	if err != nil || stored.Status != entity.StatusPending {
+		t.Errorf("reservation was cancelled by someone else")
+	}
+}
+
+func TestUnregisterVoidsPaymentWithoutFee(t *testing.T) {
+	f := newFixture(t, time.Now().Add(time.Hour), nil)
+
+	r, err := f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.service.Unregister(passengerContext(), r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := f.payments.Authorization(r.Payment.AuthorizationID)
+	if !a.Voided || a.Captured != 0 {
+		t.Errorf("got authorization %+v, want it voided without fee", a)
+	}
+
+	if _, err := f.repo.FindByID(r.ID); err == nil || f.trips.registered[r.ID] {
+		t.Errorf("reservation was not removed")
+	}
+}
+
+// A consumingHoldService is a hold service that hands over a single hold.
+type consumingHoldService struct {
+	hold.UseCase
+	h *entity.Hold
+}
+
+func (s consumingHoldService) Consume(ID entity.ID, r *entity.Reservation) (*entity.Hold, error) {
+	return s.h, nil
+}
+
+func TestRegisterReleasesHeldSeatsWhenStoringFails(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+	f.service.holdService = consumingHoldService{h: &entity.Hold{ID: "hold"}}
+	f.trips.registered["hold"] = true
+	f.repo.failCreate = true
+
+	r := newReservation()
+	r.HoldID = "hold"
+	_, err := f.service.Register(passengerContext(), r)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if f.trips.registered["hold"] {
+		t.Errorf("seats of the consumed hold were not released")
+	}
+}
+
+func TestRegisterIsOnlyAllowedForCaller(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+
+	r := newReservation()
+	r.UserID = "someone-else"
+	_, err := f.service.Register(passengerContext(), r)
+	if _, ok := err.(ForbiddenError); !ok {
+		t.Errorf("got error %v, want a ForbiddenError", err)
+	}
+
+	if len(f.repo.reservations) != 0 {
+		t.Errorf("reservation was booked for someone else")
+	}
+}
+
+func TestDeleteBatchIsOnlyAllowedToPassenger(t *testing.T) {
+	f := newFixture(t, time.Now().Add(48*time.Hour), nil)
+
+	r, err := f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.repo.reservations["other"] = &entity.Reservation{ID: "other", TripID: "trip", UserID: "someone-else", Status: entity.StatusConfirmed, Version: 1}
+
+	_, err = f.service.DeleteBatch(passengerContext(), []entity.ID{r.ID, "other"})
+	batchErr, ok := err.(BatchError)
+	if !ok {
+		t.Fatalf("got error %v, want a BatchError", err)
+	}
+
+	if _, ok := batchErr.Errs[1].(ForbiddenError); !ok {
+		t.Errorf("got error %v for the other passenger's reservation, want a ForbiddenError", batchErr.Errs[1])
+	}
+
+	stored, err := f.repo.FindByID(r.ID)
+	if err != nil || stored.Status != entity.StatusPending {
+		t.Errorf("reservation was cancelled even though the batch is invalid")
+	}
+}
+
+func TestCompleteTripCompletesConfirmedReservations(t *testing.T) {
+	f := newFixture(t, time.Now().Add(-time.Hour), &booking.Config{MaxActive: 1})
+
+	r, err := f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.repo.reservations[r.ID].Status = entity.StatusConfirmed
+
+	completed, err := f.service.CompleteTrip(context.Background(), "trip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(completed) != 1 || completed[0].Status != entity.StatusCompleted || completed[0].IsActive() {
+		t.Fatalf("got completed reservations %+v, want the reservation completed", completed)
+	}
+
+	a, _ := f.payments.Authorization(r.Payment.AuthorizationID)
+	if a.Captured != 2000 {
+		t.Errorf("got %d captured, want 2000", a.Captured)
+	}
+
+	_, err = f.service.Register(passengerContext(), newReservation())
+	if err != nil {
+		t.Errorf("completed reservation still counts as active (%s)", err)
+	}
+}
@@ -0,0 +1,589 @@
+package reservation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/audit"
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// A Format is a format reservations are exported to and imported from.
+type Format string
+
+const (
+	// FormatCSV represents comma-separated values, with a header row naming
+	// the columns, and one reservation per row.
+	FormatCSV = Format("csv")
+
+	// FormatJSONLines represents JSON Lines, with one reservation per line,
+	// encoded like in the responses of the API.
+	FormatJSONLines = Format("jsonl")
+)
+
+const (
+	// MaximumImportErrors represents the maximum number of line errors
+	// reported by an import. The lines that fail beyond it are only counted.
+	MaximumImportErrors = 100
+
+	// MaximumLineSize represents the maximum size of a line of JSON Lines, in
+	// bytes.
+	MaximumLineSize = 1 << 20
+)
+
+// csvColumns are the columns of a CSV export, in order.
+var csvColumns = []string{
+	"id",
+	"tripId",
+	"userId",
+	"sourceId",
+	"destinationId",
+	"seats",
+	"status",
+	"fareAmount",
+	"fareCurrency",
+	"paymentStatus",
+	"paymentCaptured",
+	"paymentRefunded",
+	"cancellationRule",
+	"cancellationFee",
+	"cancellationReason",
+	"cancelledAt",
+	"checkInMethod",
+	"checkInLatitude",
+	"checkInLongitude",
+	"checkedInAt",
+	"version",
+}
+
+// An ImportReport is the outcome of an import.
+type ImportReport struct {
+	DryRun bool `json:"dryRun"`
+
+	// Lines is the number of reservations that were read.
+	Lines int `json:"lines"`
+
+	// Imported is the number of reservations that were imported, or that
+	// would have been during a dry run.
+	Imported int `json:"imported"`
+
+	// Failed is the number of reservations that were not imported.
+	Failed int `json:"failed"`
+
+	// Errors contains the errors of the first MaximumImportErrors reservations
+	// that were not imported, in order.
+	Errors []*LineError `json:"errors"`
+}
+
+func (r *ImportReport) fail(line int, res *entity.Reservation, err error) {
+	r.Failed++
+	if len(r.Errors) >= MaximumImportErrors {
+		return
+	}
+
+	e := &LineError{Line: line, Err: err}
+	if res != nil {
+		e.ID = res.ID
+	}
+	r.Errors = append(r.Errors, e)
+}
+
+// A LineError is an error that represents that the reservation read from a
+// line of an import could not be imported.
+type LineError struct {
+	Line int
+	ID   entity.ID
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// MarshalJSON encodes the line error with its message and, if the reservation
+// is invalid, its invalid fields.
+func (e *LineError) MarshalJSON() ([]byte, error) {
+	var params []*entity.InvalidParam
+	if validationErr, ok := e.Err.(entity.ValidationError); ok {
+		params = validationErr.InvalidParams()
+	}
+
+	return json.Marshal(struct {
+		Line          int                    `json:"line"`
+		ID            entity.ID              `json:"id,omitempty"`
+		Error         string                 `json:"error"`
+		InvalidParams []*entity.InvalidParam `json:"invalidParams,omitempty"`
+	}{e.Line, e.ID, e.Err.Error(), params})
+}
+
+// Export writes the reservations selected by the criteria to w in the given
+// format, oldest first, and returns how many were written. The reservations
+// are streamed from the database rather than loaded in memory. Like List, it
+// does not authorize the caller, so it is only meant for operators.
+func (s *Service) Export(ctx context.Context, c *Criteria, format Format, w io.Writer) (int, error) {
+	if c == nil {
+		c = &Criteria{}
+	}
+
+	enc, err := newEncoder(format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err = s.repo.FindEach(c, func(res *entity.Reservation) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := enc.encode(res)
+		if err != nil {
+			return err
+		}
+		n++
+
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+
+	return n, enc.flush()
+}
+
+// Import reads reservations in the given format from r and stores them in the
+// database, as they are, keeping their IDs. It is meant for operators seeding
+// a database, so the reservations are neither registered on their trips nor
+// published as events; a reconciliation reports the active ones as missing on
+// their trips.
+//
+// Since check-in codes are not exported, every imported reservation is given a
+// new one, and its version starts over. A reservation whose payment is still
+// authorized is refused, since the authorization was placed for the
+// reservation it was exported from and cannot be carried over.
+//
+// Every reservation is validated, and a reservation that cannot be read, is
+// invalid or cannot be stored is reported in the import report with its line
+// without stopping the import. During a dry run, the reservations are only
+// read and validated. An error is only returned when the input cannot be read
+// any further, in which case the reservations imported so far stay imported.
+func (s *Service) Import(ctx context.Context, format Format, r io.Reader, dryRun bool) (*ImportReport, error) {
+	dec, err := newDecoder(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: dryRun, Errors: []*LineError{}}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		res, line, err := dec.decode()
+		if err == io.EOF {
+			break
+		} else if lineErr, ok := err.(*LineError); ok {
+			report.Lines++
+			report.fail(lineErr.Line, nil, lineErr.Err)
+			continue
+		} else if err != nil {
+			return report, err
+		}
+		report.Lines++
+
+		err = validateImport(res)
+		if err == nil && !dryRun {
+			err = s.importReservation(ctx, res)
+		}
+		if err != nil {
+			report.fail(line, res, err)
+			continue
+		}
+
+		report.Imported++
+	}
+
+	return report, nil
+}
+
+// validateImport ensures that the reservation is valid and can be imported.
+func validateImport(res *entity.Reservation) error {
+	err := res.Validate()
+	if err != nil {
+		return err
+	}
+
+	if res.Payment != nil && res.Payment.Status == entity.PaymentAuthorized {
+		return entity.NewFieldValidationError("paymentStatus", "cannot be authorized, the authorization must be voided or captured before exporting")
+	}
+
+	return nil
+}
+
+func (s *Service) importReservation(ctx context.Context, res *entity.Reservation) error {
+	if res.Status == "" {
+		res.Status = entity.StatusConfirmed
+	}
+
+	var err error
+	res.CheckInCode, err = newCheckInCode()
+	if err != nil {
+		return err
+	}
+	res.Version = entity.InitialVersion
+
+	ID, err := s.repo.Create(res)
+	if err != nil {
+		return err
+	}
+	res.ID = ID
+
+	s.record(ctx, audit.OperationImport, nil, res)
+
+	return nil
+}
+
+// An encoder writes reservations in a format.
+type encoder interface {
+	encode(res *entity.Reservation) error
+	flush() error
+}
+
+func newEncoder(format Format, w io.Writer) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w)
+	case FormatJSONLines:
+		bw := bufio.NewWriter(w)
+		return &jsonLinesEncoder{bw, json.NewEncoder(bw)}, nil
+	default:
+		return nil, fmt.Errorf("reservation: format \"%s\" is not supported, use %s or %s", format, FormatCSV, FormatJSONLines)
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(csvColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvEncoder{cw}, nil
+}
+
+func (e *csvEncoder) encode(res *entity.Reservation) error {
+	fields := map[string]string{
+		"id":            res.ID.Hex(),
+		"tripId":        res.TripID.Hex(),
+		"userId":        res.UserID.Hex(),
+		"sourceId":      res.SourceID.Hex(),
+		"destinationId": res.DestinationID.Hex(),
+		"seats":         strconv.Itoa(res.Seats),
+		"status":        string(res.Status),
+		"version":       strconv.Itoa(res.Version),
+	}
+
+	if res.Fare != nil {
+		fields["fareAmount"] = strconv.Itoa(res.Fare.Amount)
+		fields["fareCurrency"] = res.Fare.Currency
+	}
+
+	if res.Payment != nil {
+		fields["paymentStatus"] = string(res.Payment.Status)
+		fields["paymentCaptured"] = strconv.Itoa(res.Payment.Captured)
+		fields["paymentRefunded"] = strconv.Itoa(res.Payment.Refunded)
+	}
+
+	if res.Cancellation != nil {
+		fields["cancellationRule"] = res.Cancellation.Rule
+		fields["cancellationFee"] = strconv.Itoa(res.Cancellation.Fee)
+		fields["cancellationReason"] = res.Cancellation.Reason
+		fields["cancelledAt"] = res.Cancellation.CancelledAt.Format(time.RFC3339)
+	}
+
+	if res.CheckIn != nil {
+		fields["checkInMethod"] = string(res.CheckIn.Method)
+		fields["checkedInAt"] = res.CheckIn.CheckedInAt.Format(time.RFC3339)
+
+		if res.CheckIn.Point != nil {
+			fields["checkInLatitude"] = strconv.FormatFloat(res.CheckIn.Point.Latitude, 'f', -1, 64)
+			fields["checkInLongitude"] = strconv.FormatFloat(res.CheckIn.Point.Longitude, 'f', -1, 64)
+		}
+	}
+
+	record := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		record[i] = fields[column]
+	}
+
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonLinesEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonLinesEncoder) encode(res *entity.Reservation) error {
+	return e.enc.Encode(res)
+}
+
+func (e *jsonLinesEncoder) flush() error {
+	return e.w.Flush()
+}
+
+// A decoder reads reservations in a format. It returns each reservation with
+// the line it starts on, a *LineError when a reservation cannot be read, and
+// io.EOF once every reservation was read.
+type decoder interface {
+	decode() (*entity.Reservation, int, error)
+}
+
+func newDecoder(format Format, r io.Reader) (decoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVDecoder(r)
+	case FormatJSONLines:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), MaximumLineSize)
+		return &jsonLinesDecoder{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("reservation: format \"%s\" is not supported, use %s or %s", format, FormatCSV, FormatJSONLines)
+	}
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	columns []string
+}
+
+// newCSVDecoder reads the header row, whose columns must be columns of a CSV
+// export, in any order. Missing columns are left empty.
+func newCSVDecoder(r io.Reader) (*csvDecoder, error) {
+	cr := csv.NewReader(r)
+
+	columns, err := cr.Read()
+	if err == io.EOF {
+		return &csvDecoder{cr, nil}, nil
+	} else if err != nil {
+		return nil, entity.NewValidationError(fmt.Sprintf("CSV header is invalid (%s)", err))
+	}
+
+	known := map[string]bool{}
+	for _, column := range csvColumns {
+		known[column] = true
+	}
+	seen := map[string]bool{}
+	for _, column := range columns {
+		if !known[column] {
+			return nil, entity.NewValidationError(fmt.Sprintf("CSV header has an unknown column \"%s\"", column))
+		} else if seen[column] {
+			return nil, entity.NewValidationError(fmt.Sprintf("CSV header has the column \"%s\" more than once", column))
+		}
+		seen[column] = true
+	}
+
+	return &csvDecoder{cr, columns}, nil
+}
+
+func (d *csvDecoder) decode() (*entity.Reservation, int, error) {
+	if d.columns == nil {
+		return nil, 0, io.EOF
+	}
+
+	record, err := d.r.Read()
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		return nil, parseErr.StartLine, &LineError{Line: parseErr.StartLine, Err: entity.NewValidationError(parseErr.Err.Error())}
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	line, _ := d.r.FieldPos(0)
+
+	fields := map[string]string{}
+	for i, column := range d.columns {
+		fields[column] = record[i]
+	}
+
+	res, err := parseFields(fields)
+	if err != nil {
+		return nil, line, &LineError{Line: line, Err: err}
+	}
+
+	return res, line, nil
+}
+
+// A fieldParser parses the fields of a CSV row, collecting the fields that are
+// invalid.
+type fieldParser struct {
+	fields  map[string]string
+	invalid []*entity.InvalidParam
+}
+
+func (p *fieldParser) int(name string) int {
+	if p.fields[name] == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(p.fields[name])
+	if err != nil {
+		p.invalid = append(p.invalid, &entity.InvalidParam{Name: name, Reason: "must be an integer"})
+	}
+
+	return n
+}
+
+func (p *fieldParser) float(name string) float64 {
+	if p.fields[name] == "" {
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(p.fields[name], 64)
+	if err != nil {
+		p.invalid = append(p.invalid, &entity.InvalidParam{Name: name, Reason: "must be a number"})
+	}
+
+	return f
+}
+
+func (p *fieldParser) time(name string) time.Time {
+	if p.fields[name] == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, p.fields[name])
+	if err != nil {
+		p.invalid = append(p.invalid, &entity.InvalidParam{Name: name, Reason: "must be an RFC 3339 date and time"})
+	}
+
+	return t
+}
+
+// parseFields creates a reservation from the fields of a CSV row, by column.
+// The optional parts of a reservation, such as its fare, are only set when one
+// of their fields is not empty.
+func parseFields(fields map[string]string) (*entity.Reservation, error) {
+	p := &fieldParser{fields: fields}
+
+	res := &entity.Reservation{
+		ID:            entity.NewIDFromHex(fields["id"]),
+		TripID:        entity.NewIDFromHex(fields["tripId"]),
+		UserID:        entity.NewIDFromHex(fields["userId"]),
+		SourceID:      entity.NewIDFromHex(fields["sourceId"]),
+		DestinationID: entity.NewIDFromHex(fields["destinationId"]),
+		Seats:         p.int("seats"),
+		Status:        entity.Status(fields["status"]),
+	}
+
+	if fields["fareAmount"] != "" || fields["fareCurrency"] != "" {
+		res.Fare = &entity.Fare{
+			Amount:   p.int("fareAmount"),
+			Currency: fields["fareCurrency"],
+		}
+	}
+
+	if fields["paymentStatus"] != "" {
+		res.Payment = &entity.Payment{
+			Status:   entity.PaymentStatus(fields["paymentStatus"]),
+			Captured: p.int("paymentCaptured"),
+			Refunded: p.int("paymentRefunded"),
+		}
+	}
+
+	if fields["cancellationRule"] != "" || fields["cancelledAt"] != "" {
+		res.Cancellation = &entity.Cancellation{
+			Rule:        fields["cancellationRule"],
+			Fee:         p.int("cancellationFee"),
+			Reason:      fields["cancellationReason"],
+			CancelledAt: p.time("cancelledAt"),
+		}
+	}
+
+	if fields["checkInMethod"] != "" {
+		res.CheckIn = &entity.CheckIn{
+			Method:      entity.CheckInMethod(fields["checkInMethod"]),
+			CheckedInAt: p.time("checkedInAt"),
+		}
+
+		if fields["checkInLatitude"] != "" || fields["checkInLongitude"] != "" {
+			res.CheckIn.Point = &entity.Point{
+				Latitude:  p.float("checkInLatitude"),
+				Longitude: p.float("checkInLongitude"),
+			}
+		}
+	}
+
+	if len(p.invalid) > 0 {
+		return nil, entity.NewInvalidParamsError(p.invalid)
+	}
+
+	return res, nil
+}
+
+type jsonLinesDecoder struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// decode skips blank lines. A line longer than MaximumLineSize stops the
+// import, since the lines following it cannot be found.
+func (d *jsonLinesDecoder) decode() (*entity.Reservation, int, error) {
+	for d.scanner.Scan() {
+		d.line++
+
+		b := bytes.TrimSpace(d.scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+
+		var res entity.Reservation
+		err := dec.Decode(&res)
+		if err == nil && dec.More() {
+			err = fmt.Errorf("line must contain a single JSON value")
+		}
+		if err != nil {
+			return nil, d.line, &LineError{Line: d.line, Err: entity.NewValidationError(err.Error())}
+		}
+
+		return &res, d.line, nil
+	}
+
+	if err := d.scanner.Err(); err == bufio.ErrTooLong {
+		return nil, 0, entity.NewValidationError(fmt.Sprintf("line %d is longer than %d bytes", d.line+1, MaximumLineSize))
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	return nil, 0, io.EOF
+}
+
+// ParseTime parses a bound of a range of creation times, which is either an
+// RFC 3339 date and time, or a date standing for midnight UTC.
+func ParseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02", s)
+}
@@ -0,0 +1,64 @@
+package reservation
+
+import (
+	"context"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/identity"
+)
+
+// Watch subscribes to the changes made to the reservation with the given ID,
+// as long as the authenticated user is its passenger or the driver of its
+// trip. The subscription is closed when the context is done.
+func (s *Service) Watch(ctx context.Context, ID entity.ID) (*Subscription, error) {
+	_, err := s.FindForUser(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.watch(ctx, func(res *entity.Reservation) bool {
+		return res.ID == ID
+	}), nil
+}
+
+// WatchUser subscribes to the changes made to the authenticated user's
+// reservations. The subscription is closed when the context is done.
+func (s *Service) WatchUser(ctx context.Context) (*Subscription, error) {
+	userInfo, err := identity.FromContext(ctx)
+	if err != nil || userInfo.SubID == "" {
+		return nil, ForbiddenError{"reservation.Service: only users can watch their reservations"}
+	}
+
+	userID := entity.NewIDFromHex(userInfo.SubID)
+
+	return s.watch(ctx, func(res *entity.Reservation) bool {
+		return res.UserID == userID
+	}), nil
+}
+
+// WatchTrip subscribes to the changes made to the reservations of the trip
+// with the given ID, as long as the authenticated user is the trip's driver.
+// The subscription is closed when the context is done.
+func (s *Service) WatchTrip(ctx context.Context, tripID entity.ID) (*Subscription, error) {
+	_, err := s.authorizeDriver(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.watch(ctx, func(res *entity.Reservation) bool {
+		return res.TripID == tripID
+	}), nil
+}
+
+// watch subscribes to the changes made to the reservations selected by the
+// filter until the context is done.
+func (s *Service) watch(ctx context.Context, filter Filter) *Subscription {
+	sub := s.bus.Subscribe(filter)
+
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+
+	return sub
+}
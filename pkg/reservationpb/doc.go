@@ -0,0 +1,5 @@
+// Package reservationpb contains the protocol buffer messages and the gRPC
+// service of the reservation API, generated from reservation.proto.
+package reservationpb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. reservation.proto
@@ -0,0 +1,1433 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: reservation.proto
+
+package reservationpb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// A Reservation holds seats on a trip, from a source stop to a destination
+// stop.
+type Reservation struct {
+	Id            string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TripId        string   `protobuf:"bytes,2,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	UserId        string   `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	SourceId      string   `protobuf:"bytes,4,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	DestinationId string   `protobuf:"bytes,5,opt,name=destination_id,json=destinationId,proto3" json:"destination_id,omitempty"`
+	Seats         int32    `protobuf:"varint,6,opt,name=seats,proto3" json:"seats,omitempty"`
+	Fare          *Fare    `protobuf:"bytes,7,opt,name=fare,proto3" json:"fare,omitempty"`
+	Payment       *Payment `protobuf:"bytes,8,opt,name=payment,proto3" json:"payment,omitempty"`
+	// One of pending, confirmed, completed, rejected, cancelled,
+	// cancelled-by-driver, no-show or expired.
+	Status       string        `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	Cancellation *Cancellation `protobuf:"bytes,10,opt,name=cancellation,proto3" json:"cancellation,omitempty"`
+	CheckIn      *CheckIn      `protobuf:"bytes,11,opt,name=check_in,json=checkIn,proto3" json:"check_in,omitempty"`
+	// Incremented every time the reservation is modified.
+	Version int32 `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	// The ID of the hold consumed to book the reservation, if any.
+	HoldId               string   `protobuf:"bytes,14,opt,name=hold_id,json=holdId,proto3" json:"hold_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Reservation) Reset()         { *m = Reservation{} }
+func (m *Reservation) String() string { return proto.CompactTextString(m) }
+func (*Reservation) ProtoMessage()    {}
+func (*Reservation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{0}
+}
+
+func (m *Reservation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reservation.Unmarshal(m, b)
+}
+func (m *Reservation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reservation.Marshal(b, m, deterministic)
+}
+func (m *Reservation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reservation.Merge(m, src)
+}
+func (m *Reservation) XXX_Size() int {
+	return xxx_messageInfo_Reservation.Size(m)
+}
+func (m *Reservation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reservation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reservation proto.InternalMessageInfo
+
+func (m *Reservation) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Reservation) GetTripId() string {
+	if m != nil {
+		return m.TripId
+	}
+	return ""
+}
+
+func (m *Reservation) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *Reservation) GetSourceId() string {
+	if m != nil {
+		return m.SourceId
+	}
+	return ""
+}
+
+func (m *Reservation) GetDestinationId() string {
+	if m != nil {
+		return m.DestinationId
+	}
+	return ""
+}
+
+func (m *Reservation) GetSeats() int32 {
+	if m != nil {
+		return m.Seats
+	}
+	return 0
+}
+
+func (m *Reservation) GetFare() *Fare {
+	if m != nil {
+		return m.Fare
+	}
+	return nil
+}
+
+func (m *Reservation) GetPayment() *Payment {
+	if m != nil {
+		return m.Payment
+	}
+	return nil
+}
+
+func (m *Reservation) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Reservation) GetCancellation() *Cancellation {
+	if m != nil {
+		return m.Cancellation
+	}
+	return nil
+}
+
+func (m *Reservation) GetCheckIn() *CheckIn {
+	if m != nil {
+		return m.CheckIn
+	}
+	return nil
+}
+
+func (m *Reservation) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Reservation) GetHoldId() string {
+	if m != nil {
+		return m.HoldId
+	}
+	return ""
+}
+
+// A Fare is the price of a reservation, in the currency's smallest unit (ex.
+// cents).
+type Fare struct {
+	Amount               int64    `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency             string   `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Fare) Reset()         { *m = Fare{} }
+func (m *Fare) String() string { return proto.CompactTextString(m) }
+func (*Fare) ProtoMessage()    {}
+func (*Fare) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{1}
+}
+
+func (m *Fare) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Fare.Unmarshal(m, b)
+}
+func (m *Fare) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Fare.Marshal(b, m, deterministic)
+}
+func (m *Fare) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Fare.Merge(m, src)
+}
+func (m *Fare) XXX_Size() int {
+	return xxx_messageInfo_Fare.Size(m)
+}
+func (m *Fare) XXX_DiscardUnknown() {
+	xxx_messageInfo_Fare.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Fare proto.InternalMessageInfo
+
+func (m *Fare) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *Fare) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+// A Payment is the state of the payment of a reservation's fare.
+type Payment struct {
+	// One of authorized, captured, voided or refunded.
+	Status               string   `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Captured             int64    `protobuf:"varint,2,opt,name=captured,proto3" json:"captured,omitempty"`
+	Refunded             int64    `protobuf:"varint,3,opt,name=refunded,proto3" json:"refunded,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Payment) Reset()         { *m = Payment{} }
+func (m *Payment) String() string { return proto.CompactTextString(m) }
+func (*Payment) ProtoMessage()    {}
+func (*Payment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{2}
+}
+
+func (m *Payment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Payment.Unmarshal(m, b)
+}
+func (m *Payment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Payment.Marshal(b, m, deterministic)
+}
+func (m *Payment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Payment.Merge(m, src)
+}
+func (m *Payment) XXX_Size() int {
+	return xxx_messageInfo_Payment.Size(m)
+}
+func (m *Payment) XXX_DiscardUnknown() {
+	xxx_messageInfo_Payment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Payment proto.InternalMessageInfo
+
+func (m *Payment) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Payment) GetCaptured() int64 {
+	if m != nil {
+		return m.Captured
+	}
+	return 0
+}
+
+func (m *Payment) GetRefunded() int64 {
+	if m != nil {
+		return m.Refunded
+	}
+	return 0
+}
+
+// A Cancellation contains the details of a reservation's cancellation.
+type Cancellation struct {
+	// The cancellation policy rule that was applied.
+	Rule                 string               `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Fee                  int64                `protobuf:"varint,2,opt,name=fee,proto3" json:"fee,omitempty"`
+	Reason               string               `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	CancelledAt          *timestamp.Timestamp `protobuf:"bytes,4,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Cancellation) Reset()         { *m = Cancellation{} }
+func (m *Cancellation) String() string { return proto.CompactTextString(m) }
+func (*Cancellation) ProtoMessage()    {}
+func (*Cancellation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{3}
+}
+
+func (m *Cancellation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Cancellation.Unmarshal(m, b)
+}
+func (m *Cancellation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Cancellation.Marshal(b, m, deterministic)
+}
+func (m *Cancellation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Cancellation.Merge(m, src)
+}
+func (m *Cancellation) XXX_Size() int {
+	return xxx_messageInfo_Cancellation.Size(m)
+}
+func (m *Cancellation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Cancellation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Cancellation proto.InternalMessageInfo
+
+func (m *Cancellation) GetRule() string {
+	if m != nil {
+		return m.Rule
+	}
+	return ""
+}
+
+func (m *Cancellation) GetFee() int64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
+
+func (m *Cancellation) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Cancellation) GetCancelledAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CancelledAt
+	}
+	return nil
+}
+
+// A CheckIn contains the details of a passenger's check-in.
+type CheckIn struct {
+	// One of scan or self.
+	Method               string               `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Point                *Point               `protobuf:"bytes,2,opt,name=point,proto3" json:"point,omitempty"`
+	CheckedInAt          *timestamp.Timestamp `protobuf:"bytes,3,opt,name=checked_in_at,json=checkedInAt,proto3" json:"checked_in_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *CheckIn) Reset()         { *m = CheckIn{} }
+func (m *CheckIn) String() string { return proto.CompactTextString(m) }
+func (*CheckIn) ProtoMessage()    {}
+func (*CheckIn) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{4}
+}
+
+func (m *CheckIn) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckIn.Unmarshal(m, b)
+}
+func (m *CheckIn) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckIn.Marshal(b, m, deterministic)
+}
+func (m *CheckIn) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckIn.Merge(m, src)
+}
+func (m *CheckIn) XXX_Size() int {
+	return xxx_messageInfo_CheckIn.Size(m)
+}
+func (m *CheckIn) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckIn.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckIn proto.InternalMessageInfo
+
+func (m *CheckIn) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *CheckIn) GetPoint() *Point {
+	if m != nil {
+		return m.Point
+	}
+	return nil
+}
+
+func (m *CheckIn) GetCheckedInAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CheckedInAt
+	}
+	return nil
+}
+
+// A Point is a geographical position.
+type Point struct {
+	Latitude             float64  `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude            float64  `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Point) Reset()         { *m = Point{} }
+func (m *Point) String() string { return proto.CompactTextString(m) }
+func (*Point) ProtoMessage()    {}
+func (*Point) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{5}
+}
+
+func (m *Point) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Point.Unmarshal(m, b)
+}
+func (m *Point) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Point.Marshal(b, m, deterministic)
+}
+func (m *Point) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Point.Merge(m, src)
+}
+func (m *Point) XXX_Size() int {
+	return xxx_messageInfo_Point.Size(m)
+}
+func (m *Point) XXX_DiscardUnknown() {
+	xxx_messageInfo_Point.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Point proto.InternalMessageInfo
+
+func (m *Point) GetLatitude() float64 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
+}
+
+func (m *Point) GetLongitude() float64 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
+}
+
+type CreateReservationRequest struct {
+	TripId string `protobuf:"bytes,1,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	// The ID of the authenticated user. It can be left out, since reservations
+	// can only be booked for the authenticated user.
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	SourceId      string `protobuf:"bytes,3,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	DestinationId string `protobuf:"bytes,4,opt,name=destination_id,json=destinationId,proto3" json:"destination_id,omitempty"`
+	Seats         int32  `protobuf:"varint,5,opt,name=seats,proto3" json:"seats,omitempty"`
+	// The ID of a hold on the seats to consume, if any.
+	HoldId               string   `protobuf:"bytes,6,opt,name=hold_id,json=holdId,proto3" json:"hold_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateReservationRequest) Reset()         { *m = CreateReservationRequest{} }
+func (m *CreateReservationRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateReservationRequest) ProtoMessage()    {}
+func (*CreateReservationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{6}
+}
+
+func (m *CreateReservationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateReservationRequest.Unmarshal(m, b)
+}
+func (m *CreateReservationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateReservationRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateReservationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateReservationRequest.Merge(m, src)
+}
+func (m *CreateReservationRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateReservationRequest.Size(m)
+}
+func (m *CreateReservationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateReservationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateReservationRequest proto.InternalMessageInfo
+
+func (m *CreateReservationRequest) GetTripId() string {
+	if m != nil {
+		return m.TripId
+	}
+	return ""
+}
+
+func (m *CreateReservationRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *CreateReservationRequest) GetSourceId() string {
+	if m != nil {
+		return m.SourceId
+	}
+	return ""
+}
+
+func (m *CreateReservationRequest) GetDestinationId() string {
+	if m != nil {
+		return m.DestinationId
+	}
+	return ""
+}
+
+func (m *CreateReservationRequest) GetSeats() int32 {
+	if m != nil {
+		return m.Seats
+	}
+	return 0
+}
+
+func (m *CreateReservationRequest) GetHoldId() string {
+	if m != nil {
+		return m.HoldId
+	}
+	return ""
+}
+
+type GetReservationRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetReservationRequest) Reset()         { *m = GetReservationRequest{} }
+func (m *GetReservationRequest) String() string { return proto.CompactTextString(m) }
+func (*GetReservationRequest) ProtoMessage()    {}
+func (*GetReservationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{7}
+}
+
+func (m *GetReservationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetReservationRequest.Unmarshal(m, b)
+}
+func (m *GetReservationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetReservationRequest.Marshal(b, m, deterministic)
+}
+func (m *GetReservationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetReservationRequest.Merge(m, src)
+}
+func (m *GetReservationRequest) XXX_Size() int {
+	return xxx_messageInfo_GetReservationRequest.Size(m)
+}
+func (m *GetReservationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetReservationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetReservationRequest proto.InternalMessageInfo
+
+func (m *GetReservationRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type ListReservationsRequest struct {
+	TripId               string   `protobuf:"bytes,1,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListReservationsRequest) Reset()         { *m = ListReservationsRequest{} }
+func (m *ListReservationsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListReservationsRequest) ProtoMessage()    {}
+func (*ListReservationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{8}
+}
+
+func (m *ListReservationsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReservationsRequest.Unmarshal(m, b)
+}
+func (m *ListReservationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReservationsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListReservationsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReservationsRequest.Merge(m, src)
+}
+func (m *ListReservationsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListReservationsRequest.Size(m)
+}
+func (m *ListReservationsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReservationsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReservationsRequest proto.InternalMessageInfo
+
+func (m *ListReservationsRequest) GetTripId() string {
+	if m != nil {
+		return m.TripId
+	}
+	return ""
+}
+
+type ListReservationsResponse struct {
+	Reservations         []*Reservation `protobuf:"bytes,1,rep,name=reservations,proto3" json:"reservations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ListReservationsResponse) Reset()         { *m = ListReservationsResponse{} }
+func (m *ListReservationsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListReservationsResponse) ProtoMessage()    {}
+func (*ListReservationsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{9}
+}
+
+func (m *ListReservationsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReservationsResponse.Unmarshal(m, b)
+}
+func (m *ListReservationsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReservationsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListReservationsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReservationsResponse.Merge(m, src)
+}
+func (m *ListReservationsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListReservationsResponse.Size(m)
+}
+func (m *ListReservationsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReservationsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReservationsResponse proto.InternalMessageInfo
+
+func (m *ListReservationsResponse) GetReservations() []*Reservation {
+	if m != nil {
+		return m.Reservations
+	}
+	return nil
+}
+
+type ModifyReservationRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The ID of the reservation's trip. It is required for the modifications
+	// made by the driver.
+	TripId string `protobuf:"bytes,2,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	// When it is set, the reservation is only modified if its version matches,
+	// like the REST API's If-Match header.
+	Version int32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	// Types that are valid to be assigned to Modification:
+	//	*ModifyReservationRequest_Accept
+	//	*ModifyReservationRequest_Reject
+	//	*ModifyReservationRequest_CheckIn
+	//	*ModifyReservationRequest_SelfCheckIn
+	Modification         isModifyReservationRequest_Modification `protobuf_oneof:"modification"`
+	XXX_NoUnkeyedLiteral struct{}                                `json:"-"`
+	XXX_unrecognized     []byte                                  `json:"-"`
+	XXX_sizecache        int32                                   `json:"-"`
+}
+
+func (m *ModifyReservationRequest) Reset()         { *m = ModifyReservationRequest{} }
+func (m *ModifyReservationRequest) String() string { return proto.CompactTextString(m) }
+func (*ModifyReservationRequest) ProtoMessage()    {}
+func (*ModifyReservationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{10}
+}
+
+func (m *ModifyReservationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModifyReservationRequest.Unmarshal(m, b)
+}
+func (m *ModifyReservationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModifyReservationRequest.Marshal(b, m, deterministic)
+}
+func (m *ModifyReservationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModifyReservationRequest.Merge(m, src)
+}
+func (m *ModifyReservationRequest) XXX_Size() int {
+	return xxx_messageInfo_ModifyReservationRequest.Size(m)
+}
+func (m *ModifyReservationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModifyReservationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModifyReservationRequest proto.InternalMessageInfo
+
+func (m *ModifyReservationRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ModifyReservationRequest) GetTripId() string {
+	if m != nil {
+		return m.TripId
+	}
+	return ""
+}
+
+func (m *ModifyReservationRequest) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type isModifyReservationRequest_Modification interface {
+	isModifyReservationRequest_Modification()
+}
+
+type ModifyReservationRequest_Accept struct {
+	Accept *Acceptance `protobuf:"bytes,4,opt,name=accept,proto3,oneof"`
+}
+
+type ModifyReservationRequest_Reject struct {
+	Reject *Rejection `protobuf:"bytes,5,opt,name=reject,proto3,oneof"`
+}
+
+type ModifyReservationRequest_CheckIn struct {
+	CheckIn *CheckInScan `protobuf:"bytes,6,opt,name=check_in,json=checkIn,proto3,oneof"`
+}
+
+type ModifyReservationRequest_SelfCheckIn struct {
+	SelfCheckIn *SelfCheckIn `protobuf:"bytes,7,opt,name=self_check_in,json=selfCheckIn,proto3,oneof"`
+}
+
+func (*ModifyReservationRequest_Accept) isModifyReservationRequest_Modification() {}
+
+func (*ModifyReservationRequest_Reject) isModifyReservationRequest_Modification() {}
+
+func (*ModifyReservationRequest_CheckIn) isModifyReservationRequest_Modification() {}
+
+func (*ModifyReservationRequest_SelfCheckIn) isModifyReservationRequest_Modification() {}
+
+func (m *ModifyReservationRequest) GetModification() isModifyReservationRequest_Modification {
+	if m != nil {
+		return m.Modification
+	}
+	return nil
+}
+
+func (m *ModifyReservationRequest) GetAccept() *Acceptance {
+	if x, ok := m.GetModification().(*ModifyReservationRequest_Accept); ok {
+		return x.Accept
+	}
+	return nil
+}
+
+func (m *ModifyReservationRequest) GetReject() *Rejection {
+	if x, ok := m.GetModification().(*ModifyReservationRequest_Reject); ok {
+		return x.Reject
+	}
+	return nil
+}
+
+func (m *ModifyReservationRequest) GetCheckIn() *CheckInScan {
+	if x, ok := m.GetModification().(*ModifyReservationRequest_CheckIn); ok {
+		return x.CheckIn
+	}
+	return nil
+}
+
+func (m *ModifyReservationRequest) GetSelfCheckIn() *SelfCheckIn {
+	if x, ok := m.GetModification().(*ModifyReservationRequest_SelfCheckIn); ok {
+		return x.SelfCheckIn
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*ModifyReservationRequest) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*ModifyReservationRequest_Accept)(nil),
+		(*ModifyReservationRequest_Reject)(nil),
+		(*ModifyReservationRequest_CheckIn)(nil),
+		(*ModifyReservationRequest_SelfCheckIn)(nil),
+	}
+}
+
+type Acceptance struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Acceptance) Reset()         { *m = Acceptance{} }
+func (m *Acceptance) String() string { return proto.CompactTextString(m) }
+func (*Acceptance) ProtoMessage()    {}
+func (*Acceptance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{11}
+}
+
+func (m *Acceptance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Acceptance.Unmarshal(m, b)
+}
+func (m *Acceptance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Acceptance.Marshal(b, m, deterministic)
+}
+func (m *Acceptance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Acceptance.Merge(m, src)
+}
+func (m *Acceptance) XXX_Size() int {
+	return xxx_messageInfo_Acceptance.Size(m)
+}
+func (m *Acceptance) XXX_DiscardUnknown() {
+	xxx_messageInfo_Acceptance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Acceptance proto.InternalMessageInfo
+
+type Rejection struct {
+	Reason               string   `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Rejection) Reset()         { *m = Rejection{} }
+func (m *Rejection) String() string { return proto.CompactTextString(m) }
+func (*Rejection) ProtoMessage()    {}
+func (*Rejection) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{12}
+}
+
+func (m *Rejection) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Rejection.Unmarshal(m, b)
+}
+func (m *Rejection) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Rejection.Marshal(b, m, deterministic)
+}
+func (m *Rejection) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Rejection.Merge(m, src)
+}
+func (m *Rejection) XXX_Size() int {
+	return xxx_messageInfo_Rejection.Size(m)
+}
+func (m *Rejection) XXX_DiscardUnknown() {
+	xxx_messageInfo_Rejection.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Rejection proto.InternalMessageInfo
+
+func (m *Rejection) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type CheckInScan struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CheckInScan) Reset()         { *m = CheckInScan{} }
+func (m *CheckInScan) String() string { return proto.CompactTextString(m) }
+func (*CheckInScan) ProtoMessage()    {}
+func (*CheckInScan) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{13}
+}
+
+func (m *CheckInScan) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckInScan.Unmarshal(m, b)
+}
+func (m *CheckInScan) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckInScan.Marshal(b, m, deterministic)
+}
+func (m *CheckInScan) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckInScan.Merge(m, src)
+}
+func (m *CheckInScan) XXX_Size() int {
+	return xxx_messageInfo_CheckInScan.Size(m)
+}
+func (m *CheckInScan) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckInScan.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckInScan proto.InternalMessageInfo
+
+func (m *CheckInScan) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type SelfCheckIn struct {
+	Point                *Point   `protobuf:"bytes,1,opt,name=point,proto3" json:"point,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SelfCheckIn) Reset()         { *m = SelfCheckIn{} }
+func (m *SelfCheckIn) String() string { return proto.CompactTextString(m) }
+func (*SelfCheckIn) ProtoMessage()    {}
+func (*SelfCheckIn) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{14}
+}
+
+func (m *SelfCheckIn) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SelfCheckIn.Unmarshal(m, b)
+}
+func (m *SelfCheckIn) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SelfCheckIn.Marshal(b, m, deterministic)
+}
+func (m *SelfCheckIn) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SelfCheckIn.Merge(m, src)
+}
+func (m *SelfCheckIn) XXX_Size() int {
+	return xxx_messageInfo_SelfCheckIn.Size(m)
+}
+func (m *SelfCheckIn) XXX_DiscardUnknown() {
+	xxx_messageInfo_SelfCheckIn.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SelfCheckIn proto.InternalMessageInfo
+
+func (m *SelfCheckIn) GetPoint() *Point {
+	if m != nil {
+		return m.Point
+	}
+	return nil
+}
+
+type CancelReservationRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The ID of the reservation's trip, when the driver cancels it.
+	TripId string `protobuf:"bytes,2,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	// Why the driver cancels the reservation.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// When it is set, the reservation is only cancelled if its version matches,
+	// like the REST API's If-Match header.
+	Version              int32    `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelReservationRequest) Reset()         { *m = CancelReservationRequest{} }
+func (m *CancelReservationRequest) String() string { return proto.CompactTextString(m) }
+func (*CancelReservationRequest) ProtoMessage()    {}
+func (*CancelReservationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{15}
+}
+
+func (m *CancelReservationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelReservationRequest.Unmarshal(m, b)
+}
+func (m *CancelReservationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelReservationRequest.Marshal(b, m, deterministic)
+}
+func (m *CancelReservationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelReservationRequest.Merge(m, src)
+}
+func (m *CancelReservationRequest) XXX_Size() int {
+	return xxx_messageInfo_CancelReservationRequest.Size(m)
+}
+func (m *CancelReservationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelReservationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelReservationRequest proto.InternalMessageInfo
+
+func (m *CancelReservationRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *CancelReservationRequest) GetTripId() string {
+	if m != nil {
+		return m.TripId
+	}
+	return ""
+}
+
+func (m *CancelReservationRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *CancelReservationRequest) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type WatchReservationRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchReservationRequest) Reset()         { *m = WatchReservationRequest{} }
+func (m *WatchReservationRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchReservationRequest) ProtoMessage()    {}
+func (*WatchReservationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c1b272c5347b2042, []int{16}
+}
+
+func (m *WatchReservationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchReservationRequest.Unmarshal(m, b)
+}
+func (m *WatchReservationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchReservationRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchReservationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchReservationRequest.Merge(m, src)
+}
+func (m *WatchReservationRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchReservationRequest.Size(m)
+}
+func (m *WatchReservationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchReservationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchReservationRequest proto.InternalMessageInfo
+
+func (m *WatchReservationRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Reservation)(nil), "ecovo.reservation.v1.Reservation")
+	proto.RegisterType((*Fare)(nil), "ecovo.reservation.v1.Fare")
+	proto.RegisterType((*Payment)(nil), "ecovo.reservation.v1.Payment")
+	proto.RegisterType((*Cancellation)(nil), "ecovo.reservation.v1.Cancellation")
+	proto.RegisterType((*CheckIn)(nil), "ecovo.reservation.v1.CheckIn")
+	proto.RegisterType((*Point)(nil), "ecovo.reservation.v1.Point")
+	proto.RegisterType((*CreateReservationRequest)(nil), "ecovo.reservation.v1.CreateReservationRequest")
+	proto.RegisterType((*GetReservationRequest)(nil), "ecovo.reservation.v1.GetReservationRequest")
+	proto.RegisterType((*ListReservationsRequest)(nil), "ecovo.reservation.v1.ListReservationsRequest")
+	proto.RegisterType((*ListReservationsResponse)(nil), "ecovo.reservation.v1.ListReservationsResponse")
+	proto.RegisterType((*ModifyReservationRequest)(nil), "ecovo.reservation.v1.ModifyReservationRequest")
+	proto.RegisterType((*Acceptance)(nil), "ecovo.reservation.v1.Acceptance")
+	proto.RegisterType((*Rejection)(nil), "ecovo.reservation.v1.Rejection")
+	proto.RegisterType((*CheckInScan)(nil), "ecovo.reservation.v1.CheckInScan")
+	proto.RegisterType((*SelfCheckIn)(nil), "ecovo.reservation.v1.SelfCheckIn")
+	proto.RegisterType((*CancelReservationRequest)(nil), "ecovo.reservation.v1.CancelReservationRequest")
+	proto.RegisterType((*WatchReservationRequest)(nil), "ecovo.reservation.v1.WatchReservationRequest")
+}
+
+func init() {
+	proto.RegisterFile("reservation.proto", fileDescriptor_c1b272c5347b2042)
+}
+
+var fileDescriptor_c1b272c5347b2042 = []byte{
+	// 964 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xdf, 0x6e, 0xe3, 0xc4,
+	0x17, 0x5e, 0xc7, 0xf9, 0x7b, 0x9c, 0x46, 0xed, 0x68, 0x7f, 0xbf, 0x5a, 0x59, 0xd0, 0x66, 0x8d,
+	0x10, 0x45, 0x68, 0x1d, 0x36, 0x5c, 0x00, 0x8b, 0x58, 0xd1, 0xad, 0xf6, 0x4f, 0x10, 0x48, 0x2b,
+	0x17, 0x09, 0xc1, 0x4d, 0x98, 0xda, 0x27, 0xad, 0xd9, 0xc4, 0xe3, 0x9d, 0x19, 0x47, 0x2a, 0x6f,
+	0x80, 0xb8, 0xe5, 0x7d, 0x78, 0x22, 0xee, 0x78, 0x00, 0x34, 0x33, 0x4e, 0x32, 0x69, 0xe3, 0x26,
+	0x12, 0x77, 0x3e, 0x33, 0xdf, 0x99, 0x39, 0x73, 0xce, 0xf7, 0x7d, 0x86, 0x23, 0x8e, 0x02, 0xf9,
+	0x82, 0xca, 0x94, 0x65, 0x61, 0xce, 0x99, 0x64, 0xe4, 0x3e, 0xc6, 0x6c, 0xc1, 0x42, 0x7b, 0x63,
+	0xf1, 0xa4, 0xff, 0xf0, 0x92, 0xb1, 0xcb, 0x19, 0x0e, 0x35, 0xe6, 0xa2, 0x98, 0x0e, 0x65, 0x3a,
+	0x47, 0x21, 0xe9, 0x3c, 0x37, 0x69, 0xc1, 0x3f, 0x2e, 0x78, 0xd1, 0x3a, 0x87, 0xf4, 0xa0, 0x96,
+	0x26, 0xbe, 0x33, 0x70, 0x4e, 0x3a, 0x51, 0x2d, 0x4d, 0xc8, 0x31, 0xb4, 0x24, 0x4f, 0xf3, 0x49,
+	0x9a, 0xf8, 0x35, 0xbd, 0xd8, 0x54, 0xe1, 0x58, 0x6f, 0x14, 0x02, 0xb9, 0xda, 0x70, 0xcd, 0x86,
+	0x0a, 0xc7, 0x09, 0x79, 0x00, 0x1d, 0xc1, 0x0a, 0x1e, 0xa3, 0xda, 0xaa, 0xeb, 0xad, 0xb6, 0x59,
+	0x18, 0x27, 0xe4, 0x43, 0xe8, 0x25, 0x28, 0x64, 0x9a, 0xe9, 0xdb, 0x14, 0xa2, 0xa1, 0x11, 0x07,
+	0xd6, 0xea, 0x38, 0x21, 0xf7, 0xa1, 0x21, 0x90, 0x4a, 0xe1, 0x37, 0x07, 0xce, 0x49, 0x23, 0x32,
+	0x01, 0x09, 0xa1, 0x3e, 0xa5, 0x1c, 0xfd, 0xd6, 0xc0, 0x39, 0xf1, 0x46, 0xfd, 0x70, 0xdb, 0x8b,
+	0xc3, 0x97, 0x94, 0x63, 0xa4, 0x71, 0xe4, 0x73, 0x68, 0xe5, 0xf4, 0x7a, 0x8e, 0x99, 0xf4, 0xdb,
+	0x3a, 0xe5, 0xfd, 0xed, 0x29, 0x6f, 0x0c, 0x28, 0x5a, 0xa2, 0xc9, 0xff, 0xa1, 0x29, 0x24, 0x95,
+	0x85, 0xf0, 0x3b, 0xe6, 0x69, 0x26, 0x22, 0x2f, 0xa1, 0x1b, 0xd3, 0x2c, 0xc6, 0xd9, 0x4c, 0xe7,
+	0xfa, 0xa0, 0x4f, 0x0d, 0xb6, 0x9f, 0x7a, 0x66, 0x21, 0xa3, 0x8d, 0x3c, 0xf2, 0x05, 0xb4, 0xe3,
+	0x2b, 0x8c, 0xdf, 0x4e, 0xd2, 0xcc, 0xf7, 0xee, 0xaa, 0xec, 0x4c, 0xa1, 0xc6, 0x59, 0xd4, 0x8a,
+	0xcd, 0x07, 0xf1, 0xa1, 0xb5, 0x40, 0x2e, 0xd4, 0xe5, 0x5d, 0xdd, 0x9a, 0x65, 0xa8, 0xe6, 0x71,
+	0xc5, 0x66, 0x89, 0x6a, 0x69, 0xcf, 0x14, 0xad, 0xc2, 0x71, 0xf2, 0x6d, 0xbd, 0x7d, 0x70, 0xd8,
+	0x8b, 0x0e, 0x96, 0x17, 0x4e, 0x62, 0x96, 0x60, 0xf0, 0x14, 0xea, 0xaa, 0x51, 0xea, 0xa5, 0x74,
+	0xce, 0x8a, 0x4c, 0xea, 0x91, 0xbb, 0x51, 0x19, 0x91, 0x3e, 0xb4, 0xe3, 0x82, 0x73, 0xcc, 0xe2,
+	0xeb, 0x72, 0xee, 0xab, 0x38, 0xf8, 0x09, 0x5a, 0x6f, 0x6e, 0x35, 0xca, 0xd9, 0x68, 0x94, 0x4a,
+	0xa7, 0xb9, 0x2c, 0x38, 0x1a, 0xda, 0xb8, 0xd1, 0x2a, 0x56, 0x7b, 0x1c, 0xa7, 0x45, 0x96, 0xa0,
+	0x61, 0x8e, 0x1b, 0xad, 0xe2, 0xe0, 0x0f, 0x07, 0xba, 0x76, 0xdf, 0x08, 0x81, 0x3a, 0x2f, 0x66,
+	0x58, 0x1e, 0xaf, 0xbf, 0xc9, 0x21, 0xb8, 0x53, 0xc4, 0xf2, 0x5c, 0xf5, 0xa9, 0xca, 0xe0, 0x48,
+	0x05, 0xcb, 0x96, 0x54, 0x34, 0x11, 0xf9, 0x7a, 0x35, 0x2f, 0x4c, 0x26, 0x54, 0xfa, 0xf5, 0x92,
+	0x38, 0x46, 0x14, 0xe1, 0x52, 0x14, 0xe1, 0x0f, 0x4b, 0x51, 0x44, 0xde, 0x0a, 0x7f, 0x2a, 0x83,
+	0x3f, 0x1d, 0x68, 0x95, 0x13, 0x50, 0x57, 0xcc, 0x51, 0x5e, 0xb1, 0xa5, 0x36, 0xca, 0x88, 0x3c,
+	0x81, 0x46, 0xce, 0xd2, 0x4c, 0xea, 0x72, 0xbc, 0xd1, 0x83, 0x0a, 0x86, 0x29, 0x48, 0x64, 0x90,
+	0xe4, 0x19, 0x98, 0x61, 0x60, 0xa2, 0xc6, 0x41, 0xa5, 0xef, 0xee, 0x51, 0x96, 0x49, 0x18, 0x67,
+	0xa7, 0x32, 0x38, 0x85, 0x86, 0x3e, 0x4f, 0x75, 0x52, 0xb5, 0x49, 0x16, 0x89, 0x69, 0x90, 0x13,
+	0xad, 0x62, 0xf2, 0x1e, 0x74, 0x66, 0x2c, 0xbb, 0x34, 0x9b, 0x35, 0xbd, 0xb9, 0x5e, 0x08, 0xfe,
+	0x72, 0xc0, 0x3f, 0xe3, 0x48, 0x25, 0x5a, 0xda, 0x8f, 0xf0, 0x5d, 0x81, 0x42, 0xda, 0x92, 0x77,
+	0xaa, 0x24, 0x5f, 0xab, 0x96, 0xbc, 0xbb, 0x53, 0xf2, 0xf5, 0x3b, 0x25, 0xdf, 0xb0, 0x25, 0x6f,
+	0xb1, 0xba, 0x69, 0xb3, 0x3a, 0xf8, 0x08, 0xfe, 0xf7, 0x0a, 0xe5, 0x96, 0xea, 0x6f, 0x18, 0x58,
+	0x30, 0x82, 0xe3, 0xef, 0x52, 0x61, 0x23, 0xc5, 0xae, 0x87, 0x06, 0x14, 0xfc, 0xdb, 0x39, 0x22,
+	0x67, 0x99, 0x40, 0xf2, 0x02, 0xba, 0xd6, 0x70, 0x15, 0xf1, 0xdd, 0x13, 0x6f, 0xf4, 0x68, 0xfb,
+	0xdc, 0xed, 0xfa, 0x36, 0xd2, 0x82, 0xbf, 0x6b, 0xe0, 0x7f, 0xcf, 0x92, 0x74, 0x7a, 0xbd, 0xfb,
+	0x0d, 0xd5, 0x26, 0x6c, 0xd9, 0x81, 0xbb, 0x69, 0x07, 0x4f, 0xa1, 0x49, 0xe3, 0x18, 0xf3, 0x25,
+	0xe9, 0x07, 0xdb, 0x0b, 0x3c, 0xd5, 0x18, 0xc5, 0xf9, 0xd7, 0xf7, 0xa2, 0x32, 0x83, 0x7c, 0xa9,
+	0xe4, 0xf4, 0x2b, 0xc6, 0x52, 0xcf, 0xc2, 0x1b, 0x3d, 0xac, 0x7a, 0x9c, 0xc2, 0xa4, 0x2c, 0x53,
+	0xa9, 0x26, 0x81, 0x3c, 0xb3, 0x9c, 0xad, 0x39, 0x70, 0xaa, 0x3b, 0x53, 0xea, 0xea, 0x3c, 0xa6,
+	0x2a, 0x7d, 0xe5, 0x6f, 0xaf, 0xe0, 0x40, 0xe0, 0x6c, 0x3a, 0x59, 0x1d, 0xd2, 0xba, 0xeb, 0x90,
+	0x73, 0x9c, 0x4d, 0xcb, 0x83, 0x5e, 0xdf, 0x8b, 0x3c, 0xb1, 0x0e, 0x9f, 0xf7, 0xa0, 0x3b, 0x57,
+	0xed, 0x4d, 0x63, 0x8d, 0x0e, 0xba, 0x00, 0xeb, 0xb7, 0x06, 0x1f, 0x40, 0x67, 0x55, 0xbd, 0xe5,
+	0x1e, 0x8e, 0xed, 0x1e, 0xc1, 0x23, 0xf0, 0xac, 0x2a, 0x95, 0x15, 0x29, 0xeb, 0x5c, 0x5a, 0x91,
+	0xfa, 0x0e, 0xbe, 0x01, 0xcf, 0xaa, 0x61, 0x6d, 0x06, 0xce, 0xbe, 0x66, 0x10, 0x14, 0xe0, 0x1b,
+	0xc3, 0xfb, 0x2f, 0x34, 0xa8, 0xf2, 0x3f, 0x8b, 0x1e, 0xf5, 0x0d, 0x7a, 0x04, 0x1f, 0xc3, 0xf1,
+	0x8f, 0x54, 0xc6, 0x57, 0xbb, 0x6f, 0x1d, 0xfd, 0xde, 0x00, 0x62, 0xc1, 0xce, 0x91, 0x2f, 0xd2,
+	0x18, 0xc9, 0x14, 0x8e, 0x6e, 0x39, 0x08, 0x09, 0x2b, 0x86, 0x5d, 0x61, 0x35, 0xfd, 0xdd, 0xb2,
+	0x21, 0xbf, 0x40, 0x6f, 0x53, 0xe8, 0xe4, 0x93, 0xed, 0x49, 0x5b, 0xed, 0x60, 0x9f, 0x1b, 0xde,
+	0xc1, 0xe1, 0x4d, 0xb5, 0x93, 0xc7, 0xdb, 0xd3, 0x2a, 0x9c, 0xa4, 0x1f, 0xee, 0x0b, 0x2f, 0x4d,
+	0x64, 0x0a, 0x47, 0xb7, 0xc4, 0x5f, 0xd5, 0xbc, 0x2a, 0x97, 0xd8, 0xe7, 0x69, 0x6a, 0x48, 0x37,
+	0xd9, 0x55, 0x39, 0xa4, 0x0a, 0x1a, 0xee, 0x77, 0xcf, 0xe1, 0x4d, 0x3a, 0x55, 0xb5, 0xb0, 0x82,
+	0x76, 0x7b, 0xdc, 0xf2, 0xa9, 0xf3, 0xfc, 0xc5, 0xcf, 0x67, 0xf4, 0xb7, 0x82, 0x63, 0x18, 0xb3,
+	0xf9, 0x50, 0xe3, 0x87, 0x16, 0xfe, 0xb1, 0x30, 0xdc, 0x1c, 0xe6, 0x6f, 0x2f, 0xed, 0xf5, 0xfc,
+	0xe2, 0xab, 0x8d, 0xe8, 0xa2, 0xa9, 0x7f, 0xb1, 0x9f, 0xfd, 0x3b, 0x00, 0x52, 0x3b, 0xa6, 0x10,
+	0x47, 0x0b, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ReservationServiceClient is the client API for ReservationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ReservationServiceClient interface {
+	// CreateReservation books seats on a trip for the authenticated user.
+	CreateReservation(ctx context.Context, in *CreateReservationRequest, opts ...grpc.CallOption) (*Reservation, error)
+	// GetReservation retrieves a reservation by its ID. Only its passenger and
+	// the driver of its trip can retrieve it.
+	GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*Reservation, error)
+	// ListReservations lists the reservations of a trip. Only the trip's driver
+	// can list them.
+	ListReservations(ctx context.Context, in *ListReservationsRequest, opts ...grpc.CallOption) (*ListReservationsResponse, error)
+	// ModifyReservation accepts, rejects or checks in a reservation.
+	ModifyReservation(ctx context.Context, in *ModifyReservationRequest, opts ...grpc.CallOption) (*Reservation, error)
+	// CancelReservation cancels a reservation, as its passenger or as the driver
+	// of its trip.
+	CancelReservation(ctx context.Context, in *CancelReservationRequest, opts ...grpc.CallOption) (*Reservation, error)
+	// WatchReservation streams a reservation, once when the call starts and
+	// then every time it changes, until the call is cancelled. Only its
+	// passenger and the driver of its trip can watch it.
+	WatchReservation(ctx context.Context, in *WatchReservationRequest, opts ...grpc.CallOption) (ReservationService_WatchReservationClient, error)
+}
+
+type reservationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReservationServiceClient(cc grpc.ClientConnInterface) ReservationServiceClient {
+	return &reservationServiceClient{cc}
+}
+
+func (c *reservationServiceClient) CreateReservation(ctx context.Context, in *CreateReservationRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, "/ecovo.reservation.v1.ReservationService/CreateReservation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, "/ecovo.reservation.v1.ReservationService/GetReservation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) ListReservations(ctx context.Context, in *ListReservationsRequest, opts ...grpc.CallOption) (*ListReservationsResponse, error) {
+	out := new(ListReservationsResponse)
+	err := c.cc.Invoke(ctx, "/ecovo.reservation.v1.ReservationService/ListReservations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) ModifyReservation(ctx context.Context, in *ModifyReservationRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, "/ecovo.reservation.v1.ReservationService/ModifyReservation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) CancelReservation(ctx context.Context, in *CancelReservationRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, "/ecovo.reservation.v1.ReservationService/CancelReservation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) WatchReservation(ctx context.Context, in *WatchReservationRequest, opts ...grpc.CallOption) (ReservationService_WatchReservationClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ReservationService_serviceDesc.Streams[0], "/ecovo.reservation.v1.ReservationService/WatchReservation", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &reservationServiceWatchReservationClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ReservationService_WatchReservationClient interface {
+	Recv() (*Reservation, error)
+	grpc.ClientStream
+}
+
+type reservationServiceWatchReservationClient struct {
+	grpc.ClientStream
+}
+
+func (x *reservationServiceWatchReservationClient) Recv() (*Reservation, error) {
+	m := new(Reservation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReservationServiceServer is the server API for ReservationService service.
+type ReservationServiceServer interface {
+	// CreateReservation books seats on a trip for the authenticated user.
+	CreateReservation(context.Context, *CreateReservationRequest) (*Reservation, error)
+	// GetReservation retrieves a reservation by its ID. Only its passenger and
+	// the driver of its trip can retrieve it.
+	GetReservation(context.Context, *GetReservationRequest) (*Reservation, error)
+	// ListReservations lists the reservations of a trip. Only the trip's driver
+	// can list them.
+	ListReservations(context.Context, *ListReservationsRequest) (*ListReservationsResponse, error)
+	// ModifyReservation accepts, rejects or checks in a reservation.
+	ModifyReservation(context.Context, *ModifyReservationRequest) (*Reservation, error)
+	// CancelReservation cancels a reservation, as its passenger or as the driver
+	// of its trip.
+	CancelReservation(context.Context, *CancelReservationRequest) (*Reservation, error)
+	// WatchReservation streams a reservation, once when the call starts and
+	// then every time it changes, until the call is cancelled. Only its
+	// passenger and the driver of its trip can watch it.
+	WatchReservation(*WatchReservationRequest, ReservationService_WatchReservationServer) error
+}
+
+// UnimplementedReservationServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedReservationServiceServer struct {
+}
+
+func (*UnimplementedReservationServiceServer) CreateReservation(ctx context.Context, req *CreateReservationRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReservation not implemented")
+}
+func (*UnimplementedReservationServiceServer) GetReservation(ctx context.Context, req *GetReservationRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReservation not implemented")
+}
+func (*UnimplementedReservationServiceServer) ListReservations(ctx context.Context, req *ListReservationsRequest) (*ListReservationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReservations not implemented")
+}
+func (*UnimplementedReservationServiceServer) ModifyReservation(ctx context.Context, req *ModifyReservationRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModifyReservation not implemented")
+}
+func (*UnimplementedReservationServiceServer) CancelReservation(ctx context.Context, req *CancelReservationRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelReservation not implemented")
+}
+func (*UnimplementedReservationServiceServer) WatchReservation(req *WatchReservationRequest, srv ReservationService_WatchReservationServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchReservation not implemented")
+}
+
+func RegisterReservationServiceServer(s *grpc.Server, srv ReservationServiceServer) {
+	s.RegisterService(&_ReservationService_serviceDesc, srv)
+}
+
+func _ReservationService_CreateReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CreateReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecovo.reservation.v1.ReservationService/CreateReservation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CreateReservation(ctx, req.(*CreateReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_GetReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).GetReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecovo.reservation.v1.ReservationService/GetReservation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).GetReservation(ctx, req.(*GetReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_ListReservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).ListReservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecovo.reservation.v1.ReservationService/ListReservations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).ListReservations(ctx, req.(*ListReservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_ModifyReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModifyReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).ModifyReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecovo.reservation.v1.ReservationService/ModifyReservation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).ModifyReservation(ctx, req.(*ModifyReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_CancelReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CancelReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecovo.reservation.v1.ReservationService/CancelReservation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CancelReservation(ctx, req.(*CancelReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_WatchReservation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchReservationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReservationServiceServer).WatchReservation(m, &reservationServiceWatchReservationServer{stream})
+}
+
+type ReservationService_WatchReservationServer interface {
+	Send(*Reservation) error
+	grpc.ServerStream
+}
+
+type reservationServiceWatchReservationServer struct {
+	grpc.ServerStream
+}
+
+func (x *reservationServiceWatchReservationServer) Send(m *Reservation) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _ReservationService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "ecovo.reservation.v1.ReservationService",
+	HandlerType: (*ReservationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReservation",
+			Handler:    _ReservationService_CreateReservation_Handler,
+		},
+		{
+			MethodName: "GetReservation",
+			Handler:    _ReservationService_GetReservation_Handler,
+		},
+		{
+			MethodName: "ListReservations",
+			Handler:    _ReservationService_ListReservations_Handler,
+		},
+		{
+			MethodName: "ModifyReservation",
+			Handler:    _ReservationService_ModifyReservation_Handler,
+		},
+		{
+			MethodName: "CancelReservation",
+			Handler:    _ReservationService_CancelReservation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchReservation",
+			Handler:       _ReservationService_WatchReservation_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "reservation.proto",
+}
@@ -7,6 +7,8 @@ import (
 // Repository is an interface representing the ability to perform CRUD
 // operations on trip-service.
 type Repository interface {
+	FindByID(ID entity.ID) (*entity.Trip, error)
+	FindReservations(tripID entity.ID) ([]*entity.Reservation, error)
 	CreateReservation(res *entity.Reservation) (*entity.Reservation, error)
 	DeleteReservation(res *entity.Reservation) error
 }
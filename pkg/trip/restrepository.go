@@ -28,6 +28,71 @@ func NewRestRepository(domain string, authToken string) (Repository, error) {
 	return &RestRepository{domain, authToken}, nil
 }
 
+// FindByID retrieves the trip with the given ID.
+func (r *RestRepository) FindByID(ID entity.ID) (*entity.Trip, error) {
+	req, err := http.NewRequest("GET", "http://"+r.domain+"/trips/"+ID.Hex(), nil)
+	if err != nil {
+		return nil, RequestError{fmt.Sprintf("trip.restrepository: failed to create request (%s)", err)}
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", r.authToken))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NotFoundError{fmt.Sprintf("trip.restrepository: no trip found with ID \"%s\"", ID)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, RequestError{fmt.Sprintf("trip.restrepository: failed request to trip-service (status %d)", resp.StatusCode)}
+	}
+
+	var t entity.Trip
+	err = json.NewDecoder(resp.Body).Decode(&t)
+	if err != nil {
+		return nil, fmt.Errorf("trip.restrepository: failed to decode trip (%s)", err)
+	}
+
+	return &t, nil
+}
+
+// FindReservations retrieves the reservations registered on the trip with the
+// given ID.
+func (r *RestRepository) FindReservations(tripID entity.ID) ([]*entity.Reservation, error) {
+	req, err := http.NewRequest("GET", "http://"+r.domain+"/trips/"+tripID.Hex()+"/reservations", nil)
+	if err != nil {
+		return nil, RequestError{fmt.Sprintf("trip.restrepository: failed to create request (%s)", err)}
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", r.authToken))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NotFoundError{fmt.Sprintf("trip.restrepository: no trip found with ID \"%s\"", tripID)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, RequestError{fmt.Sprintf("trip.restrepository: failed request to trip-service (status %d)", resp.StatusCode)}
+	}
+
+	reservations := []*entity.Reservation{}
+	err = json.NewDecoder(resp.Body).Decode(&reservations)
+	if err != nil {
+		return nil, fmt.Errorf("trip.restrepository: failed to decode reservations (%s)", err)
+	}
+
+	return reservations, nil
+}
+
 // CreateReservation creates a reservation on a trip.
 func (r *RestRepository) CreateReservation(res *entity.Reservation) (*entity.Reservation, error) {
 	b := new(bytes.Buffer)
@@ -64,7 +129,7 @@ func (r *RestRepository) DeleteReservation(res *entity.Reservation) error {
 		return fmt.Errorf("trip.restrepository: failed to encode reservation")
 	}
 
-	req, err := http.NewRequest("DELETE", "https://"+r.domain+"/trips/{id}/reservation", b)
+	req, err := http.NewRequest("DELETE", "http://"+r.domain+"/trips/"+res.TripID.Hex()+"/reservation", b)
 	if err != nil {
 		return RequestError{fmt.Sprintf("trip.restrepository: failed to create request (%s)", err)}
 	}
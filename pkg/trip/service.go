@@ -9,6 +9,8 @@ import (
 // UseCase is an interface representing the ability to handle the business
 // logic that involves trips.
 type UseCase interface {
+	FindByID(ID entity.ID) (*entity.Trip, error)
+	FindReservations(tripID entity.ID) ([]*entity.Reservation, error)
 	RegisterReservation(r *entity.Reservation) error
 	DeleteReservation(r *entity.Reservation) error
 }
@@ -24,6 +26,35 @@ func NewService(repo Repository) *Service {
 	return &Service{repo}
 }
 
+// FindByID retrieves the trip with the given ID from the trip-service.
+func (s *Service) FindByID(ID entity.ID) (*entity.Trip, error) {
+	if ID.IsZero() {
+		return nil, fmt.Errorf("trip.Service: ID is nil")
+	}
+
+	t, err := s.repo.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// FindReservations retrieves the reservations registered on the trip with the
+// given ID, as seen by the trip-service.
+func (s *Service) FindReservations(tripID entity.ID) ([]*entity.Reservation, error) {
+	if tripID.IsZero() {
+		return nil, fmt.Errorf("trip.Service: trip ID is nil")
+	}
+
+	reservations, err := s.repo.FindReservations(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
 // RegisterReservation will send a creation request to the rest repository that communicates with the trip-service.
 func (s *Service) RegisterReservation(r *entity.Reservation) error {
 	if r == nil {
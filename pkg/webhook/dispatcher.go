@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+const (
+	// SignatureHeader is the name of the header containing the HMAC-SHA256
+	// signature of a delivery's body, computed with the webhook's secret.
+	SignatureHeader = "X-Ecovo-Signature"
+
+	// EventTypeHeader is the name of the header containing the type of the
+	// delivered event.
+	EventTypeHeader = "X-Ecovo-Event"
+
+	// DeliveryIDHeader is the name of the header containing the unique
+	// identifier of a delivery, which stays the same across retries.
+	DeliveryIDHeader = "X-Ecovo-Delivery"
+)
+
+// DispatcherConfig contains the information required to configure a
+// dispatcher.
+type DispatcherConfig struct {
+	// Interval specifies how often the dispatcher looks for deliveries to
+	// attempt.
+	Interval time.Duration
+
+	// Timeout specifies how long to wait for a webhook to respond.
+	Timeout time.Duration
+
+	// MaxAttempts specifies how many times a delivery is attempted before it
+	// is dead-lettered.
+	MaxAttempts int
+
+	// Backoff specifies how long to wait before the first retry. The delay
+	// doubles after every failed attempt.
+	Backoff time.Duration
+
+	// BatchSize specifies how many deliveries are attempted at every
+	// interval.
+	BatchSize int
+}
+
+const (
+	// DefaultInterval represents the default interval at which deliveries
+	// are attempted.
+	DefaultInterval = 5 * time.Second
+
+	// DefaultTimeout represents the default time to wait for a webhook to
+	// respond.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxAttempts represents the default number of attempts before a
+	// delivery is dead-lettered.
+	DefaultMaxAttempts = 8
+
+	// DefaultBackoff represents the default time to wait before the first
+	// retry.
+	DefaultBackoff = 30 * time.Second
+
+	// DefaultBatchSize represents the default number of deliveries attempted
+	// at every interval.
+	DefaultBatchSize = 50
+)
+
+// Validate looks at the configuration's contents to ensure it has all the
+// required fields.
+func (conf *DispatcherConfig) validate() error {
+	if conf.Interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	if conf.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	if conf.MaxAttempts <= 0 {
+		return errors.New("max attempts must be positive")
+	}
+
+	if conf.Backoff <= 0 {
+		return errors.New("backoff must be positive")
+	}
+
+	if conf.BatchSize <= 0 {
+		return errors.New("batch size must be positive")
+	}
+
+	return nil
+}
+
+// A Dispatcher sends the queued deliveries to their webhooks, retrying failed
+// deliveries with an exponential backoff until they are dead-lettered.
+//
+// Deliveries are sent at least once: a webhook should use the delivery ID to
+// ignore duplicates.
+type Dispatcher struct {
+	repo   Repository
+	conf   *DispatcherConfig
+	client *http.Client
+}
+
+// NewDispatcher creates a dispatcher that sends the deliveries stored in the
+// given repository.
+func NewDispatcher(repo Repository, conf *DispatcherConfig) (*Dispatcher, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("webhook.Dispatcher: repository is nil")
+	}
+
+	if conf == nil {
+		return nil, fmt.Errorf("webhook.Dispatcher: missing configuration")
+	}
+
+	err := conf.validate()
+	if err != nil {
+		return nil, fmt.Errorf("webhook.Dispatcher: configuration %s", err)
+	}
+
+	return &Dispatcher{repo, conf, &http.Client{Timeout: conf.Timeout}}, nil
+}
+
+// Run attempts the deliveries that are due at every interval, until the
+// context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := d.DispatchDue()
+			if err != nil {
+				log.Printf("webhook.Dispatcher: %s", err)
+			}
+		}
+	}
+}
+
+// DispatchDue attempts every delivery that is due.
+func (d *Dispatcher) DispatchDue() error {
+	deliveries, err := d.repo.FindDueDeliveries(time.Now().UTC(), d.conf.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		err = d.Dispatch(delivery)
+		if err != nil {
+			log.Printf("webhook.Dispatcher: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Dispatch makes an attempt to send the delivery to its webhook and records
+// its outcome. A delivery whose webhook no longer exists is dead-lettered.
+func (d *Dispatcher) Dispatch(delivery *entity.Delivery) error {
+	now := time.Now().UTC()
+	attempt := &entity.DeliveryAttempt{At: now}
+
+	w, err := d.repo.FindByID(delivery.WebhookID)
+	if err != nil {
+		attempt.Error = "webhook no longer exists"
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		delivery.Status = entity.DeliveryDead
+
+		return d.repo.UpdateDelivery(delivery)
+	}
+
+	attempt.StatusCode, err = d.send(w, delivery)
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+
+	switch {
+	case err == nil:
+		delivery.Status = entity.DeliveryDelivered
+	case len(delivery.Attempts) >= d.conf.MaxAttempts:
+		delivery.Status = entity.DeliveryDead
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(len(delivery.Attempts)))
+	}
+
+	return d.repo.UpdateDelivery(delivery)
+}
+
+// backoff returns how long to wait before the next attempt, given the number
+// of attempts that were made so far.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	return d.conf.Backoff * time.Duration(1<<uint(attempts-1))
+}
+
+func (d *Dispatcher) send(w *entity.Webhook, delivery *entity.Delivery) (int, error) {
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request (%s)", err)
+	}
+
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	req.Header.Set(SignatureHeader, "sha256="+Sign(w.Secret, delivery.Payload))
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(DeliveryIDHeader, delivery.ID.Hex())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make request (%s)", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign computes the hex encoded HMAC-SHA256 signature of the payload with the
+// given secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
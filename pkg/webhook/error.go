@@ -0,0 +1,30 @@
+package webhook
+
+// A NotFoundError is an error that represents that no webhook was found.
+type NotFoundError struct {
+	msg string
+}
+
+func (e NotFoundError) Error() string {
+	return e.msg
+}
+
+// A DeliveryNotFoundError is an error that represents that no delivery was
+// found.
+type DeliveryNotFoundError struct {
+	msg string
+}
+
+func (e DeliveryNotFoundError) Error() string {
+	return e.msg
+}
+
+// A NotReplayableError is an error that represents that a delivery cannot be
+// replayed, because it is still queued.
+type NotReplayableError struct {
+	msg string
+}
+
+func (e NotReplayableError) Error() string {
+	return e.msg
+}
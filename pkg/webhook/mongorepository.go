@@ -0,0 +1,376 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/primitive"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/options"
+)
+
+// A MongoRepository is a repository that performs CRUD operations on webhooks
+// and their deliveries in MongoDB collections.
+type MongoRepository struct {
+	webhooks   *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+type document struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	URL        string             `bson:"url"`
+	EventTypes []string           `bson:"eventTypes"`
+	Secret     string             `bson:"secret"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+}
+
+func newDocumentFromEntity(w *entity.Webhook) (*document, error) {
+	if w == nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: entity is nil")
+	}
+
+	ID, err := getObjectID(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &document{
+		ID:         ID,
+		URL:        w.URL,
+		EventTypes: w.EventTypes,
+		Secret:     w.Secret,
+		CreatedAt:  w.CreatedAt,
+	}, nil
+}
+
+func (d document) Entity() *entity.Webhook {
+	return &entity.Webhook{
+		ID:         entity.NewIDFromHex(d.ID.Hex()),
+		URL:        d.URL,
+		EventTypes: d.EventTypes,
+		Secret:     d.Secret,
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+type deliveryDocument struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	WebhookID     primitive.ObjectID `bson:"webhookId"`
+	EventID       string             `bson:"eventId"`
+	EventType     string             `bson:"eventType"`
+	Payload       []byte             `bson:"payload"`
+	Status        string             `bson:"status"`
+	Attempts      []attemptDocument  `bson:"attempts"`
+	NextAttemptAt time.Time          `bson:"nextAttemptAt"`
+	CreatedAt     time.Time          `bson:"createdAt"`
+}
+
+type attemptDocument struct {
+	At         time.Time `bson:"at"`
+	StatusCode int       `bson:"statusCode"`
+	Error      string    `bson:"error"`
+}
+
+func newDeliveryDocumentFromEntity(d *entity.Delivery) (*deliveryDocument, error) {
+	if d == nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: entity is nil")
+	}
+
+	ID, err := getObjectID(d.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookID, err := getObjectID(d.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]attemptDocument, 0, len(d.Attempts))
+	for _, a := range d.Attempts {
+		attempts = append(attempts, attemptDocument{
+			At:         a.At,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+		})
+	}
+
+	return &deliveryDocument{
+		ID:            ID,
+		WebhookID:     webhookID,
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        string(d.Status),
+		Attempts:      attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+	}, nil
+}
+
+func (d deliveryDocument) Entity() *entity.Delivery {
+	attempts := make([]*entity.DeliveryAttempt, 0, len(d.Attempts))
+	for _, a := range d.Attempts {
+		attempts = append(attempts, &entity.DeliveryAttempt{
+			At:         a.At,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+		})
+	}
+
+	return &entity.Delivery{
+		ID:            entity.NewIDFromHex(d.ID.Hex()),
+		WebhookID:     entity.NewIDFromHex(d.WebhookID.Hex()),
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        entity.DeliveryStatus(d.Status),
+		Attempts:      attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+// NewMongoRepository creates a webhook repository for MongoDB collections.
+func NewMongoRepository(webhooks *mongo.Collection, deliveries *mongo.Collection) (Repository, error) {
+	if webhooks == nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: webhooks collection is nil")
+	}
+
+	if deliveries == nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: deliveries collection is nil")
+	}
+
+	return &MongoRepository{webhooks, deliveries}, nil
+}
+
+// FindByID retrieves the webhook with the given ID, if it exists.
+func (r *MongoRepository) FindByID(ID entity.ID) (*entity.Webhook, error) {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "_id", Value: objectID}}
+	var d document
+	err = r.webhooks.FindOne(context.TODO(), filter).Decode(&d)
+	if err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: no webhook found with ID \"%s\" (%s)", ID, err)
+	}
+
+	return d.Entity(), nil
+}
+
+// FindAll retrieves every webhook.
+func (r *MongoRepository) FindAll() ([]*entity.Webhook, error) {
+	return r.find(bson.D{})
+}
+
+// FindByEventType retrieves the webhooks that subscribed to events of the
+// given type.
+func (r *MongoRepository) FindByEventType(eventType string) ([]*entity.Webhook, error) {
+	return r.find(bson.D{{Key: "eventTypes", Value: eventType}})
+}
+
+func (r *MongoRepository) find(filter interface{}) ([]*entity.Webhook, error) {
+	cur, err := r.webhooks.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: failed to find webhooks (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	webhooks := []*entity.Webhook{}
+	for cur.Next(context.TODO()) {
+		var d document
+		err := cur.Decode(&d)
+		if err != nil {
+			return nil, fmt.Errorf("webhook.MongoRepository: failed to decode webhook (%s)", err)
+		}
+
+		webhooks = append(webhooks, d.Entity())
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: failed to find webhooks (%s)", err)
+	}
+
+	return webhooks, nil
+}
+
+// Create stores the new webhook in the database and returns the unique
+// identifier that was generated for it.
+func (r *MongoRepository) Create(w *entity.Webhook) (entity.ID, error) {
+	d, err := newDocumentFromEntity(w)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("webhook.MongoRepository: failed to create webhook document from entity (%s)", err)
+	}
+
+	resp, err := r.webhooks.InsertOne(context.TODO(), d)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("webhook.MongoRepository: failed to create webhook (%s)", err)
+	}
+
+	ID, ok := resp.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return entity.NilID, fmt.Errorf("webhook.MongoRepository: failed to get ID of created webhook")
+	}
+
+	return entity.ID(ID.Hex()), nil
+}
+
+// Delete removes the webhook with the given ID from the database.
+func (r *MongoRepository) Delete(ID entity.ID) error {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return fmt.Errorf("webhook.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "_id", Value: objectID}}
+	_, err = r.webhooks.DeleteOne(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("webhook.MongoRepository: failed to delete webhook with ID \"%s\" (%s)", ID, err)
+	}
+
+	return nil
+}
+
+// FindDeliveryByID retrieves the delivery with the given ID, if it exists.
+func (r *MongoRepository) FindDeliveryByID(ID entity.ID) (*entity.Delivery, error) {
+	objectID, err := primitive.ObjectIDFromHex(ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "_id", Value: objectID}}
+	var d deliveryDocument
+	err = r.deliveries.FindOne(context.TODO(), filter).Decode(&d)
+	if err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: no delivery found with ID \"%s\" (%s)", ID, err)
+	}
+
+	return d.Entity(), nil
+}
+
+// FindDeliveriesByWebhookID retrieves the most recent deliveries made to the
+// webhook with the given ID, from the newest to the oldest.
+func (r *MongoRepository) FindDeliveriesByWebhookID(webhookID entity.ID, limit int) ([]*entity.Delivery, error) {
+	objectID, err := primitive.ObjectIDFromHex(webhookID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: failed to create object ID")
+	}
+
+	filter := bson.D{{Key: "webhookId", Value: objectID}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(int64(limit))
+
+	return r.findDeliveries(filter, opts)
+}
+
+// FindDueDeliveries retrieves the pending deliveries that should be attempted
+// at the given time, from the oldest to the newest.
+func (r *MongoRepository) FindDueDeliveries(now time.Time, limit int) ([]*entity.Delivery, error) {
+	filter := bson.D{
+		{Key: "status", Value: string(entity.DeliveryPending)},
+		{Key: "nextAttemptAt", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	return r.findDeliveries(filter, opts)
+}
+
+// FindDeadDeliveries retrieves the deliveries that were dead-lettered, from the
+// oldest to the newest.
+func (r *MongoRepository) FindDeadDeliveries(limit int) ([]*entity.Delivery, error) {
+	filter := bson.D{{Key: "status", Value: string(entity.DeliveryDead)}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	return r.findDeliveries(filter, opts)
+}
+
+func (r *MongoRepository) findDeliveries(filter interface{}, opts *options.FindOptions) ([]*entity.Delivery, error) {
+	cur, err := r.deliveries.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: failed to find deliveries (%s)", err)
+	}
+	defer cur.Close(context.TODO())
+
+	deliveries := []*entity.Delivery{}
+	for cur.Next(context.TODO()) {
+		var d deliveryDocument
+		err := cur.Decode(&d)
+		if err != nil {
+			return nil, fmt.Errorf("webhook.MongoRepository: failed to decode delivery (%s)", err)
+		}
+
+		deliveries = append(deliveries, d.Entity())
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("webhook.MongoRepository: failed to find deliveries (%s)", err)
+	}
+
+	return deliveries, nil
+}
+
+// CreateDelivery stores the new delivery in the database and returns the
+// unique identifier that was generated for it.
+func (r *MongoRepository) CreateDelivery(d *entity.Delivery) (entity.ID, error) {
+	doc, err := newDeliveryDocumentFromEntity(d)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("webhook.MongoRepository: failed to create delivery document from entity (%s)", err)
+	}
+
+	resp, err := r.deliveries.InsertOne(context.TODO(), doc)
+	if err != nil {
+		return entity.NilID, fmt.Errorf("webhook.MongoRepository: failed to create delivery (%s)", err)
+	}
+
+	ID, ok := resp.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return entity.NilID, fmt.Errorf("webhook.MongoRepository: failed to get ID of created delivery")
+	}
+
+	return entity.ID(ID.Hex()), nil
+}
+
+// UpdateDelivery updates the delivery in the database.
+func (r *MongoRepository) UpdateDelivery(d *entity.Delivery) error {
+	doc, err := newDeliveryDocumentFromEntity(d)
+	if err != nil {
+		return fmt.Errorf("webhook.MongoRepository: failed to create delivery document from entity (%s)", err)
+	}
+
+	filter := bson.D{{Key: "_id", Value: doc.ID}}
+	update := bson.D{{Key: "$set", Value: doc}}
+	resp, err := r.deliveries.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("webhook.MongoRepository: failed to update delivery with ID \"%s\" (%s)", d.ID, err)
+	}
+
+	if resp.MatchedCount <= 0 {
+		return fmt.Errorf("webhook.MongoRepository: no matching delivery was found")
+	}
+
+	return nil
+}
+
+// Gets an object ID from an entity of type ID
+func getObjectID(rawID entity.ID) (primitive.ObjectID, error) {
+	if rawID.IsZero() {
+		return primitive.NilObjectID, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(rawID.Hex())
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("webhook.MongoRepository: failed to create object")
+	}
+	return objectID, nil
+}
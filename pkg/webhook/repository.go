@@ -0,0 +1,24 @@
+package webhook
+
+import (
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+)
+
+// Repository is an interface representing the ability to perform CRUD
+// operations on webhooks and their deliveries in a database.
+type Repository interface {
+	FindByID(ID entity.ID) (*entity.Webhook, error)
+	FindAll() ([]*entity.Webhook, error)
+	FindByEventType(eventType string) ([]*entity.Webhook, error)
+	Create(w *entity.Webhook) (entity.ID, error)
+	Delete(ID entity.ID) error
+
+	FindDeliveryByID(ID entity.ID) (*entity.Delivery, error)
+	FindDeliveriesByWebhookID(webhookID entity.ID, limit int) ([]*entity.Delivery, error)
+	FindDeadDeliveries(limit int) ([]*entity.Delivery, error)
+	FindDueDeliveries(now time.Time, limit int) ([]*entity.Delivery, error)
+	CreateDelivery(d *entity.Delivery) (entity.ID, error)
+	UpdateDelivery(d *entity.Delivery) error
+}
@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"azure.com/ecovo/reservation-service/pkg/entity"
+	"azure.com/ecovo/reservation-service/pkg/event"
+)
+
+const (
+	// DefaultDeliveriesLimit represents the default number of deliveries
+	// retrieved when listing a webhook's deliveries.
+	DefaultDeliveriesLimit = 20
+
+	// MaximumDeliveriesLimit represents the maximum number of deliveries that
+	// can be retrieved when listing a webhook's deliveries.
+	MaximumDeliveriesLimit = 100
+)
+
+// UseCase is an interface representing the ability to handle the business
+// logic that involves webhooks.
+type UseCase interface {
+	Register(w *entity.Webhook) (*entity.Webhook, error)
+	FindByID(ID entity.ID) (*entity.Webhook, error)
+	FindAll() ([]*entity.Webhook, error)
+	Delete(ID entity.ID) error
+	FindDeliveries(webhookID entity.ID, limit int) ([]*entity.Delivery, error)
+	Replay(deliveryID entity.ID) (*entity.Delivery, error)
+	ReplayDead(limit int) ([]*entity.Delivery, error)
+}
+
+// A Service handles the business logic related to webhooks.
+//
+// It is also a publisher: publishing an event queues a delivery for each
+// webhook that subscribed to its type, which is then sent by a dispatcher.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a webhook service to handle business logic and
+// manipulate webhooks through a repository.
+func NewService(repo Repository) *Service {
+	return &Service{repo}
+}
+
+// Register validates and stores a new webhook.
+func (s *Service) Register(w *entity.Webhook) (*entity.Webhook, error) {
+	if w == nil {
+		return nil, fmt.Errorf("webhook.Service: webhook is nil")
+	}
+
+	err := w.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range w.EventTypes {
+		if !event.Type(t).IsValid() {
+			return nil, entity.NewFieldValidationError("eventTypes", fmt.Sprintf("contains unknown event type \"%s\"", t))
+		}
+	}
+
+	w.ID = entity.NilID
+	w.CreatedAt = time.Now().UTC()
+
+	w.ID, err = s.repo.Create(w)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// FindByID retrieves the webhook with the given ID, if it exists.
+func (s *Service) FindByID(ID entity.ID) (*entity.Webhook, error) {
+	w, err := s.repo.FindByID(ID)
+	if err != nil {
+		return nil, NotFoundError{err.Error()}
+	}
+
+	return w, nil
+}
+
+// FindAll retrieves every webhook.
+func (s *Service) FindAll() ([]*entity.Webhook, error) {
+	return s.repo.FindAll()
+}
+
+// Delete removes the webhook with the given ID. Its pending deliveries will no
+// longer be attempted.
+func (s *Service) Delete(ID entity.ID) error {
+	_, err := s.FindByID(ID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ID)
+}
+
+// FindDeliveries retrieves the most recent deliveries made to the webhook with
+// the given ID.
+func (s *Service) FindDeliveries(webhookID entity.ID, limit int) ([]*entity.Delivery, error) {
+	_, err := s.FindByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultDeliveriesLimit
+	} else if limit > MaximumDeliveriesLimit {
+		limit = MaximumDeliveriesLimit
+	}
+
+	return s.repo.FindDeliveriesByWebhookID(webhookID, limit)
+}
+
+// Replay queues the delivery with the given ID again, as a new delivery of the
+// same event with no attempts, and returns the new delivery. A dead delivery is
+// marked as replayed, so that it is not replayed twice; a delivered one is
+// left as is. A delivery that is still pending cannot be replayed.
+func (s *Service) Replay(deliveryID entity.ID) (*entity.Delivery, error) {
+	d, err := s.repo.FindDeliveryByID(deliveryID)
+	if err != nil {
+		return nil, DeliveryNotFoundError{err.Error()}
+	}
+
+	return s.replay(d)
+}
+
+// ReplayDead replays the oldest dead deliveries, up to the given limit, and
+// returns the new deliveries.
+func (s *Service) ReplayDead(limit int) ([]*entity.Delivery, error) {
+	if limit <= 0 {
+		limit = DefaultDeliveriesLimit
+	} else if limit > MaximumDeliveriesLimit {
+		limit = MaximumDeliveriesLimit
+	}
+
+	dead, err := s.repo.FindDeadDeliveries(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed := []*entity.Delivery{}
+	for _, d := range dead {
+		r, err := s.replay(d)
+		if err != nil {
+			return replayed, err
+		}
+		replayed = append(replayed, r)
+	}
+
+	return replayed, nil
+}
+
+func (s *Service) replay(d *entity.Delivery) (*entity.Delivery, error) {
+	if d.Status == entity.DeliveryPending {
+		return nil, NotReplayableError{fmt.Sprintf("webhook.Service: delivery with ID \"%s\" is still pending", d.ID)}
+	}
+
+	now := time.Now().UTC()
+	r := &entity.Delivery{
+		WebhookID:     d.WebhookID,
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        entity.DeliveryPending,
+		Attempts:      []*entity.DeliveryAttempt{},
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	var err error
+	r.ID, err = s.repo.CreateDelivery(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Status == entity.DeliveryDead {
+		d.Status = entity.DeliveryReplayed
+
+		err = s.repo.UpdateDelivery(d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Publish queues a delivery of the event for each webhook that subscribed to
+// its type.
+func (s *Service) Publish(e *event.Event) error {
+	if e == nil {
+		return fmt.Errorf("webhook.Service: event is nil")
+	}
+
+	webhooks, err := s.repo.FindByEventType(string(e.Type))
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("webhook.Service: failed to encode event (%s)", err)
+	}
+
+	now := time.Now().UTC()
+	for _, w := range webhooks {
+		d := &entity.Delivery{
+			WebhookID:     w.ID,
+			EventID:       e.ID,
+			EventType:     string(e.Type),
+			Payload:       payload,
+			Status:        entity.DeliveryPending,
+			Attempts:      []*entity.DeliveryAttempt{},
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+
+		_, err = s.repo.CreateDelivery(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}